@@ -0,0 +1,71 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+)
+
+// HTTP answer protocol: the client POSTs a gob-encoded pir.Query and the
+// server replies with a gob-encoded pir.Answer.
+const ContentType = "application/x-gob"
+
+// Bound on the size of a query accepted by Handler.
+const maxQueryBytes = 64 << 20
+
+func Handler[T matrix.Elem](server *pir.Server[T]) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST a gob-encoded query", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := new(pir.Query[T])
+		body := http.MaxBytesReader(w, r.Body, maxQueryBytes)
+		if err := gob.NewDecoder(body).Decode(query); err != nil {
+			http.Error(w, "bad query: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := server.CheckQuery(query); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(server.Answer(query)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(buf.Bytes())
+	})
+}
+
+func PostQuery[T matrix.Elem](url string, query *pir.Query[T]) (*pir.Answer[T], error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(query); err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Post(url, ContentType, &buf)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s: %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	ans := new(pir.Answer[T])
+	if err := gob.NewDecoder(resp.Body).Decode(ans); err != nil {
+		return nil, fmt.Errorf("%s: bad answer: %w", url, err)
+	}
+	return ans, nil
+}
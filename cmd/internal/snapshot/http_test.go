@@ -0,0 +1,54 @@
+package snapshot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func testHTTP[T matrix.Elem](t *testing.T) {
+	prg := rand.NewRandomBufPRG()
+	db := pir.NewDatabaseRandom[T](prg, 1<<12, 8)
+	server := pir.NewServer(db)
+	client := pir.NewClient(server.Hint(), server.MatrixA(), server.DBInfo())
+
+	ts := httptest.NewServer(Handler(server))
+	defer ts.Close()
+
+	for _, i := range []uint64{0, 1000} {
+		secret, query := client.Query(i)
+		ans, err := PostQuery(ts.URL, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := client.Recover(secret, ans); got != db.GetElem(i) {
+			t.Fatalf("record %d: got %d, expected %d", i, got, db.GetElem(i))
+		}
+	}
+
+	// Malformed queries are rejected
+	short := &pir.Query[T]{Query: matrix.Zeros[T](3, 1)}
+	if _, err := PostQuery(ts.URL, short); err == nil {
+		t.Fatal("expected error for a query of the wrong size")
+	}
+	resp, err := http.Post(ts.URL, ContentType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("empty query: got %s", resp.Status)
+	}
+}
+
+func TestHTTP32(t *testing.T) {
+	testHTTP[matrix.Elem32](t)
+}
+
+func TestHTTP64(t *testing.T) {
+	testHTTP[matrix.Elem64](t)
+}
@@ -0,0 +1,187 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Record file formats. Every record is an unsigned integer of at most 64
+// bits; its index is its position in the file.
+const (
+	FormatBinary = "binary" // fixed-width little-endian records
+	FormatLines  = "lines"  // one integer per line (decimal, or 0x-prefixed hex)
+	FormatCSV    = "csv"    // one integer per row, in a given column
+)
+
+type RecordFormat struct {
+	Format string
+	Width  int  // bytes per record, for FormatBinary
+	Column int  // 0-based, for FormatCSV
+	Header bool // skip the first row, for FormatCSV
+}
+
+func ReadRecords(r io.Reader, f RecordFormat) ([]uint64, error) {
+	switch f.Format {
+	case FormatBinary:
+		return readBinary(r, f.Width)
+	case FormatLines:
+		return readLines(r)
+	case FormatCSV:
+		return readCSV(r, f.Column, f.Header)
+	default:
+		return nil, fmt.Errorf("unknown record format %q", f.Format)
+	}
+}
+
+// Smallest number of bits holding every record (at least 1).
+func RecordBits(records []uint64) uint64 {
+	max := uint64(1)
+	for _, r := range records {
+		if l := uint64(bits.Len64(r)); l > max {
+			max = l
+		}
+	}
+	return max
+}
+
+func readBinary(r io.Reader, width int) ([]uint64, error) {
+	if width < 1 || width > 8 {
+		return nil, fmt.Errorf("record width must be 1 to 8 bytes, got %d", width)
+	}
+
+	var out []uint64
+	br := bufio.NewReader(r)
+	buf := make([]byte, 8)
+	for {
+		for i := range buf {
+			buf[i] = 0
+		}
+		_, err := io.ReadFull(br, buf[:width])
+		if err == io.EOF {
+			return out, nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("file size is not a multiple of %d bytes", width)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, binary.LittleEndian.Uint64(buf))
+	}
+}
+
+func readLines(r io.Reader) ([]uint64, error) {
+	var out []uint64
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		v, err := strconv.ParseUint(strings.TrimSpace(sc.Text()), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		out = append(out, v)
+	}
+	return out, sc.Err()
+}
+
+func readCSV(r io.Reader, column int, header bool) ([]uint64, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var out []uint64
+	for row := 1; ; row++ {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header && row == 1 {
+			continue
+		}
+		if column < 0 || column >= len(fields) {
+			return nil, fmt.Errorf("row %d has no column %d", row, column)
+		}
+
+		v, err := strconv.ParseUint(strings.TrimSpace(fields[column]), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		out = append(out, v)
+	}
+}
+
+// Registers the -format, -width, -column and -header flags on the default
+// flag set.
+func RecordFlags() *RecordFormat {
+	f := new(RecordFormat)
+	flag.StringVar(&f.Format, "format", FormatLines, "record format: binary, lines or csv")
+	flag.IntVar(&f.Width, "width", 8, "bytes per record, for -format binary")
+	flag.IntVar(&f.Column, "column", 0, "0-based column holding the records, for -format csv")
+	flag.BoolVar(&f.Header, "header", false, "skip the first row, for -format csv")
+	return f
+}
+
+// Reads the key of each record from 'column' of a CSV records file.
+func ReadRecordKeys(r io.Reader, f RecordFormat, column int) ([]string, error) {
+	if f.Format != FormatCSV {
+		return nil, fmt.Errorf("keys need -format csv")
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var out []string
+	for row := 1; ; row++ {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if f.Header && row == 1 {
+			continue
+		}
+		if column < 0 || column >= len(fields) {
+			return nil, fmt.Errorf("row %d has no column %d", row, column)
+		}
+		out = append(out, strings.TrimSpace(fields[column]))
+	}
+}
+
+func ReadRecordKeyFile(fn string, f RecordFormat, column int) ([]string, error) {
+	file, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	keys, err := ReadRecordKeys(file, f, column)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	return keys, nil
+}
+
+func ReadRecordFile(fn string, f RecordFormat) ([]uint64, error) {
+	file, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := ReadRecords(file, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,196 @@
+// Package snapshot reads and writes the files making up a PIR database
+// snapshot, shared by the pirbuild and pirquery commands.
+//
+// A snapshot is a directory containing:
+//
+//	dbinfo.gob  the gob-encoded pir.DBInfo, including the LWE params
+//	seed.bin    the 16 raw bytes of the seed of the A matrix
+//	hint.gob    the hint, a gob-encoded matrix.Matrix (L rows of n elements)
+//	server.gob  the gob-encoded pir.Server: params, packed database and hint
+//	keys.gob    optional: a gob-encoded []string, the unique key of each record
+//
+// Clients need dbinfo.gob, seed.bin and hint.gob, and keys.gob to look
+// records up by key; only the server needs server.gob. The element type
+// (32 or 64 bits) is DBInfo.Params.Logq.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+const (
+	InfoFile   = "dbinfo.gob"
+	SeedFile   = "seed.bin"
+	HintFile   = "hint.gob"
+	ServerFile = "server.gob"
+	KeysFile   = "keys.gob"
+)
+
+func Write[T matrix.Elem](dir string, server *pir.Server[T]) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		val  any
+	}{
+		{InfoFile, server.DBInfo()},
+		{HintFile, server.Hint()},
+		{ServerFile, server},
+	}
+	for _, f := range files {
+		if err := writeGob(filepath.Join(dir, f.name), f.val); err != nil {
+			return err
+		}
+	}
+
+	seed := server.MatrixA()
+	return os.WriteFile(filepath.Join(dir, SeedFile), seed[:], 0644)
+}
+
+func ReadInfo(dir string) (*pir.DBInfo, error) {
+	info := new(pir.DBInfo)
+	if err := readGob(filepath.Join(dir, InfoFile), info); err != nil {
+		return nil, err
+	}
+	if info.Params == nil {
+		return nil, fmt.Errorf("%s: missing LWE params", InfoFile)
+	}
+	return info, nil
+}
+
+func ReadSeed(dir string) (*rand.PRGKey, error) {
+	buf, err := os.ReadFile(filepath.Join(dir, SeedFile))
+	if err != nil {
+		return nil, err
+	}
+
+	seed := new(rand.PRGKey)
+	if len(buf) != len(seed) {
+		return nil, fmt.Errorf("%s: expected %d bytes, got %d", SeedFile, len(seed), len(buf))
+	}
+	copy(seed[:], buf)
+	return seed, nil
+}
+
+func ReadHint[T matrix.Elem](dir string) (*matrix.Matrix[T], error) {
+	hint := new(matrix.Matrix[T])
+	if err := readGob(filepath.Join(dir, HintFile), hint); err != nil {
+		return nil, err
+	}
+	return hint, nil
+}
+
+// The server cannot produce hint chunks or its seed: use ReadSeed.
+func ReadServer[T matrix.Elem](dir string) (*pir.Server[T], error) {
+	server := new(pir.Server[T])
+	if err := readGob(filepath.Join(dir, ServerFile), server); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// Key i names record i. Keys must be unique.
+func WriteKeys(dir string, keys []string) error {
+	if _, err := keyIndex(keys); err != nil {
+		return err
+	}
+	return writeGob(filepath.Join(dir, KeysFile), keys)
+}
+
+// Maps each key to the index of its record.
+func ReadKeys(dir string) (map[string]uint64, error) {
+	var keys []string
+	if err := readGob(filepath.Join(dir, KeysFile), &keys); err != nil {
+		return nil, err
+	}
+
+	index, err := keyIndex(keys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", KeysFile, err)
+	}
+	return index, nil
+}
+
+func keyIndex(keys []string) (map[string]uint64, error) {
+	index := make(map[string]uint64, len(keys))
+	for i, k := range keys {
+		if j, ok := index[k]; ok {
+			return nil, fmt.Errorf("records %d and %d have the same key %q", j, i, k)
+		}
+		index[k] = uint64(i)
+	}
+	return index, nil
+}
+
+// Loads everything a client needs from the snapshot.
+func ReadClient[T matrix.Elem](dir string) (*pir.Client[T], error) {
+	return readClient(dir, func(hint *matrix.Matrix[T], seed *rand.PRGKey, info *pir.DBInfo) *pir.Client[T] {
+		return pir.NewClient(hint, seed, info)
+	})
+}
+
+// Like ReadClient, but the client draws its secrets from 'key', so that a
+// query can be rebuilt later to recover a saved answer (see
+// pir.NewClientWithKey).
+func ReadClientWithKey[T matrix.Elem](dir string, key *rand.PRGKey) (*pir.Client[T], error) {
+	return readClient(dir, func(hint *matrix.Matrix[T], seed *rand.PRGKey, info *pir.DBInfo) *pir.Client[T] {
+		return pir.NewClientWithKey(hint, seed, info, key)
+	})
+}
+
+func readClient[T matrix.Elem](dir string,
+	newClient func(*matrix.Matrix[T], *rand.PRGKey, *pir.DBInfo) *pir.Client[T]) (*pir.Client[T], error) {
+	info, err := ReadInfo(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info.Params.Logq != T(0).Bitlen() {
+		return nil, fmt.Errorf("snapshot uses %d-bit elements", info.Params.Logq)
+	}
+
+	seed, err := ReadSeed(dir)
+	if err != nil {
+		return nil, err
+	}
+	hint, err := ReadHint[T](dir)
+	if err != nil {
+		return nil, err
+	}
+	if hint.Rows() != info.L || hint.Cols() != info.Params.N {
+		return nil, fmt.Errorf("%s: hint is %d-by-%d, expected %d-by-%d",
+			HintFile, hint.Rows(), hint.Cols(), info.L, info.Params.N)
+	}
+
+	return newClient(hint, seed, info), nil
+}
+
+func writeGob(fn string, val any) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(val); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(fn), err)
+	}
+	return os.WriteFile(fn, buf.Bytes(), 0644)
+}
+
+func readGob(fn string, val any) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := gob.NewDecoder(f).Decode(val); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(fn), err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package snapshot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func TestReadRecords(t *testing.T) {
+	tests := []struct {
+		in  string
+		f   RecordFormat
+		out []uint64
+	}{
+		{"\x01\x00\x02\x01\xff\xff", RecordFormat{Format: FormatBinary, Width: 2}, []uint64{1, 258, 65535}},
+		{"5\n 0x10\n7\n", RecordFormat{Format: FormatLines}, []uint64{5, 16, 7}},
+		{"id,val\na,3\nb,9\n", RecordFormat{Format: FormatCSV, Column: 1, Header: true}, []uint64{3, 9}},
+	}
+
+	for _, test := range tests {
+		out, err := ReadRecords(strings.NewReader(test.in), test.f)
+		if err != nil {
+			t.Fatalf("%s: %v", test.f.Format, err)
+		}
+		if len(out) != len(test.out) {
+			t.Fatalf("%s: got %v, expected %v", test.f.Format, out, test.out)
+		}
+		for i := range out {
+			if out[i] != test.out[i] {
+				t.Fatalf("%s: got %v, expected %v", test.f.Format, out, test.out)
+			}
+		}
+	}
+
+	bad := []struct {
+		in string
+		f  RecordFormat
+	}{
+		{"\x01\x00\x02", RecordFormat{Format: FormatBinary, Width: 2}},
+		{"1", RecordFormat{Format: FormatBinary, Width: 9}},
+		{"1\nx\n", RecordFormat{Format: FormatLines}},
+		{"1\n\n2\n", RecordFormat{Format: FormatLines}},
+		{"1,2\n3\n", RecordFormat{Format: FormatCSV, Column: 1}},
+		{"1", RecordFormat{Format: "json"}},
+	}
+	for _, test := range bad {
+		if _, err := ReadRecords(strings.NewReader(test.in), test.f); err == nil {
+			t.Fatalf("%s: expected error for %q", test.f.Format, test.in)
+		}
+	}
+
+	keys, err := ReadRecordKeys(strings.NewReader("id,val\na,3\n b ,9\n"), RecordFormat{Format: FormatCSV, Header: true}, 0)
+	if err != nil || len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("ReadRecordKeys: got %q (%v)", keys, err)
+	}
+	if _, err := ReadRecordKeys(strings.NewReader("1\n"), RecordFormat{Format: FormatLines}, 0); err == nil {
+		t.Fatal("ReadRecordKeys: expected error for lines")
+	}
+
+	if b := RecordBits([]uint64{0, 5, 300}); b != 9 {
+		t.Fatalf("RecordBits: got %d, expected 9", b)
+	}
+}
+
+func testSnapshot[T matrix.Elem](t *testing.T) {
+	dir := t.TempDir()
+	prg := rand.NewRandomBufPRG()
+	db := pir.NewDatabaseRandom[T](prg, 1<<12, 8)
+	server := pir.NewServer(db)
+	if err := Write(dir, server); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := ReadClient[T](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadServer[T](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range []uint64{0, 17, 1<<12 - 1} {
+		secret, query := client.Query(i)
+		got := client.Recover(secret, loaded.Answer(query))
+		if got != db.GetElem(i) {
+			t.Fatalf("record %d: got %d, expected %d", i, got, db.GetElem(i))
+		}
+	}
+
+	// Keys name records by index
+	if err := WriteKeys(dir, []string{"a", "b", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := ReadKeys(dir)
+	if err != nil || len(keys) != 3 || keys["c"] != 2 {
+		t.Fatalf("ReadKeys: got %v (%v)", keys, err)
+	}
+	if err := WriteKeys(dir, []string{"a", "b", "a"}); err == nil {
+		t.Fatal("expected error writing duplicate keys")
+	}
+
+	// The same key rebuilds the same query, to recover a saved answer
+	key := rand.RandomPRGKey()
+	var secrets []*pir.Secret[T]
+	var queries []*pir.Query[T]
+	var clients []*pir.Client[T]
+	for i := 0; i < 2; i++ {
+		c, err := ReadClientWithKey[T](dir, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secret, query := c.Query(17)
+		clients = append(clients, c)
+		secrets = append(secrets, secret)
+		queries = append(queries, query)
+	}
+	if !queries[0].Query.Equals(queries[1].Query) {
+		t.Fatal("same key gave different queries")
+	}
+	if got := clients[1].Recover(secrets[1], loaded.Answer(queries[0])); got != db.GetElem(17) {
+		t.Fatalf("record 17: got %d, expected %d", got, db.GetElem(17))
+	}
+
+	// Wrong element width
+	if T(0).Bitlen() == 32 {
+		if _, err := ReadClient[matrix.Elem64](dir); err == nil {
+			t.Fatal("expected error loading a 32-bit snapshot as 64-bit")
+		}
+	}
+}
+
+func TestSnapshot32(t *testing.T) {
+	testSnapshot[matrix.Elem32](t)
+}
+
+func TestSnapshot64(t *testing.T) {
+	testSnapshot[matrix.Elem64](t)
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+func main() {
+	time.Sleep(10 * time.Millisecond)
+	fmt.Println("hello", os.Args[1:])
+	os.Exit(3)
+}
@@ -0,0 +1,309 @@
+// Package wasmexec runs Go js/wasm binaries, e.g. test binaries, without
+// Node: the wasm_exec.js glue shipped with Go runs in goja, a JavaScript
+// interpreter written in Go, and the module itself in wazero. Only what the
+// glue needs is provided: console, TextEncoder and TextDecoder,
+// crypto.getRandomValues, performance.now, timers and
+// WebAssembly.Instance.
+package wasmexec
+
+import (
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dop251/goja"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Returns the wasm_exec.js of the Go toolchain on the PATH, which must be
+// the one that built the binaries run with it.
+func Glue() (string, error) {
+	out, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return "", err
+	}
+	root := strings.TrimSpace(string(out))
+
+	// Moved to lib/wasm in Go 1.24
+	for _, dir := range []string{"lib/wasm", "misc/wasm"} {
+		buf, err := os.ReadFile(filepath.Join(root, dir, "wasm_exec.js"))
+		if err == nil {
+			return string(buf), nil
+		}
+	}
+	return "", fmt.Errorf("no wasm_exec.js in %s", root)
+}
+
+// Polyfills for the browser APIs wasm_exec.js relies on, over the natives
+// installed by newHost.
+const prelude = `
+globalThis.console = {
+	log: (...args) => __write(1, args.join(" ") + "\n"),
+	warn: (...args) => __write(2, args.join(" ") + "\n"),
+	error: (...args) => __write(2, args.join(" ") + "\n"),
+};
+globalThis.TextEncoder = class {
+	encode(s) { return new Uint8Array(__encode(String(s))); }
+};
+globalThis.TextDecoder = class {
+	decode(v) {
+		if (v === undefined) return "";
+		const b = v instanceof ArrayBuffer ? v : v.buffer;
+		return __decode(b.slice(v.byteOffset || 0, (v.byteOffset || 0) + v.byteLength));
+	}
+};
+globalThis.crypto = {
+	getRandomValues(a) {
+		new Uint8Array(a.buffer, a.byteOffset, a.byteLength).set(new Uint8Array(__random(a.byteLength)));
+		return a;
+	},
+};
+globalThis.performance = { now: __now };
+globalThis.setTimeout = __setTimeout;
+globalThis.clearTimeout = __clearTimeout;
+globalThis.WebAssembly = {
+	Instance: class {
+		constructor(exports) { this.exports = exports; }
+	},
+};
+`
+
+type timer struct {
+	id int64
+	at time.Time
+	fn goja.Callable
+}
+
+type host struct {
+	vm     *goja.Runtime
+	stdout io.Writer
+	stderr io.Writer
+	start  time.Time
+
+	timers []timer
+	nextID int64
+
+	exited bool
+	code   int
+}
+
+func newHost(stdout, stderr io.Writer) *host {
+	h := &host{vm: goja.New(), stdout: stdout, stderr: stderr, start: time.Now(), nextID: 1}
+	vm := h.vm
+
+	set := func(name string, fn any) {
+		if err := vm.Set(name, fn); err != nil {
+			panic(err)
+		}
+	}
+	set("__write", func(fd int, s string) {
+		if fd == 2 {
+			io.WriteString(h.stderr, s)
+		} else {
+			io.WriteString(h.stdout, s)
+		}
+	})
+	set("__encode", func(s string) goja.ArrayBuffer {
+		return vm.NewArrayBuffer([]byte(s))
+	})
+	set("__decode", func(b goja.ArrayBuffer) string {
+		return strings.ToValidUTF8(string(b.Bytes()), string(utf8.RuneError))
+	})
+	set("__random", func(n int) goja.ArrayBuffer {
+		buf := make([]byte, n)
+		crand.Read(buf)
+		return vm.NewArrayBuffer(buf)
+	})
+	set("__now", func() float64 {
+		return float64(time.Since(h.start)) / float64(time.Millisecond)
+	})
+	set("__setTimeout", func(fn goja.Callable, delay float64) int64 {
+		t := timer{id: h.nextID, at: time.Now().Add(time.Duration(delay * float64(time.Millisecond))), fn: fn}
+		h.nextID++
+		h.timers = append(h.timers, t)
+		return t.id
+	})
+	set("__clearTimeout", func(id int64) {
+		for i, t := range h.timers {
+			if t.id == id {
+				h.timers = append(h.timers[:i], h.timers[i+1:]...)
+				return
+			}
+		}
+	})
+
+	if _, err := vm.RunString(prelude); err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// Runs the binary with 'args' (not including the program name) and returns
+// its exit code. 'glue' is the source of wasm_exec.js, see Glue.
+func Run(ctx context.Context, wasm []byte, glue string, args []string, stdout, stderr io.Writer) (int, error) {
+	h := newHost(stdout, stderr)
+	vm := h.vm
+	if _, err := vm.RunScript("wasm_exec.js", glue); err != nil {
+		return 0, err
+	}
+
+	goVal, err := vm.RunString("new Go()")
+	if err != nil {
+		return 0, err
+	}
+	goObj := goVal.ToObject(vm)
+	goObj.Set("argv", append([]string{"js"}, args...))
+	goObj.Set("env", map[string]any{"TMPDIR": os.TempDir()})
+	goObj.Set("exit", func(code int) {
+		h.exited = true
+		h.code = code
+	})
+
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	compiled, err := r.CompileModule(ctx, wasm)
+	if err != nil {
+		return 0, err
+	}
+	if err := h.importGlue(ctx, r, compiled, goObj); err != nil {
+		return 0, err
+	}
+	mod, err := r.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithStartFunctions())
+	if err != nil {
+		return 0, err
+	}
+
+	run, ok := goja.AssertFunction(goObj.Get("run"))
+	if !ok {
+		return 0, errors.New("wasm_exec.js: Go has no run method")
+	}
+	instance, err := vm.New(vm.Get("WebAssembly").ToObject(vm).Get("Instance"), h.exports(ctx, mod))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := run(goObj, instance); err != nil {
+		return 0, err
+	}
+
+	// The event loop: Go only runs again from timers or callbacks
+	for !h.exited {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if len(h.timers) == 0 {
+			// Deadlock: as Node does, make Go report it and exit
+			resume, _ := vm.RunString("(go) => { go._pendingEvent = { id: 0 }; go._resume(); }")
+			fn, _ := goja.AssertFunction(resume)
+			if _, err := fn(goja.Undefined(), goObj); err != nil {
+				return 0, err
+			}
+			if !h.exited {
+				return 0, errors.New("deadlock")
+			}
+			break
+		}
+
+		sort.SliceStable(h.timers, func(i, j int) bool { return h.timers[i].at.Before(h.timers[j].at) })
+		t := h.timers[0]
+		h.timers = h.timers[1:]
+		if d := time.Until(t.at); d > 0 {
+			time.Sleep(d)
+		}
+		if _, err := t.fn(goja.Undefined()); err != nil {
+			return 0, err
+		}
+	}
+
+	return h.code, nil
+}
+
+// Registers the functions of go.importObject that the module imports.
+func (h *host) importGlue(ctx context.Context, r wazero.Runtime, compiled wazero.CompiledModule, goObj *goja.Object) error {
+	imports := goObj.Get("importObject").ToObject(h.vm)
+	builders := map[string]wazero.HostModuleBuilder{}
+
+	for _, def := range compiled.ImportedFunctions() {
+		module, name, _ := def.Import()
+		modObj := imports.Get(module)
+		if modObj == nil || goja.IsUndefined(modObj) {
+			return fmt.Errorf("wasm_exec.js does not provide module %q", module)
+		}
+		fn, ok := goja.AssertFunction(modObj.ToObject(h.vm).Get(name))
+		if !ok {
+			return fmt.Errorf("wasm_exec.js does not provide %s.%s", module, name)
+		}
+		if len(def.ParamTypes()) != 1 || len(def.ResultTypes()) != 0 {
+			return fmt.Errorf("%s.%s: unexpected signature", module, name)
+		}
+
+		b, ok := builders[module]
+		if !ok {
+			b = r.NewHostModuleBuilder(module)
+			builders[module] = b
+		}
+		b.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, _ api.Module, stack []uint64) {
+			if _, err := fn(goja.Undefined(), h.vm.ToValue(int64(int32(stack[0])))); err != nil {
+				panic(err)
+			}
+		}), []api.ValueType{def.ParamTypes()[0]}, nil).Export(name)
+	}
+
+	for _, b := range builders {
+		if _, err := b.Instantiate(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The exports object of the WebAssembly.Instance: functions, and 'mem'
+// whose buffer aliases the module's memory.
+func (h *host) exports(ctx context.Context, mod api.Module) *goja.Object {
+	vm := h.vm
+	exports := vm.NewObject()
+
+	for name := range mod.ExportedFunctionDefinitions() {
+		exports.Set(name, func(call goja.FunctionCall) goja.Value {
+			params := make([]uint64, len(call.Arguments))
+			for i, a := range call.Arguments {
+				params[i] = uint64(a.ToInteger())
+			}
+			// A fresh handle, as calls may nest through callbacks
+			res, err := mod.ExportedFunction(name).Call(ctx, params...)
+			if err != nil {
+				panic(vm.NewGoError(err))
+			}
+			if len(res) == 0 {
+				return goja.Undefined()
+			}
+			return vm.ToValue(int64(int32(res[0])))
+		})
+	}
+
+	// Growing the memory may move it; the glue then asks for the buffer
+	// again (see resetMemoryDataView)
+	var buf []byte
+	var arrayBuf goja.ArrayBuffer
+	mem := vm.NewObject()
+	mem.DefineAccessorProperty("buffer", vm.ToValue(func() goja.ArrayBuffer {
+		data, _ := mod.Memory().Read(0, mod.Memory().Size())
+		if len(data) != len(buf) || (len(data) > 0 && &data[0] != &buf[0]) {
+			buf, arrayBuf = data, vm.NewArrayBuffer(data)
+		}
+		return arrayBuf
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	exports.Set("mem", mem)
+
+	return exports
+}
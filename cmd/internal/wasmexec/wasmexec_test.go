@@ -0,0 +1,57 @@
+package wasmexec
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func build(t *testing.T, pkg string) []byte {
+	bin := filepath.Join(t.TempDir(), "prog.wasm")
+	cmd := exec.Command("go", "build", "-o", bin, pkg)
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm", "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	wasm, err := os.ReadFile(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wasm
+}
+
+func testRun(t *testing.T, pkg string, args []string, wantCode int, wantOut string) {
+	if testing.Short() {
+		t.Skip("builds for js/wasm")
+	}
+	glue, err := Glue()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code, err := Run(context.Background(), build(t, pkg), glue, args, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != wantCode {
+		t.Fatalf("Exit code %d instead of %d\n%s%s", code, wantCode, stdout.String(), stderr.String())
+	}
+	if out := stdout.String() + stderr.String(); !strings.Contains(out, wantOut) {
+		t.Fatalf("Output %q does not contain %q", out, wantOut)
+	}
+}
+
+// Arguments, output, timers and the exit code.
+func TestExit(t *testing.T) {
+	testRun(t, "./testdata/exit", []string{"a", "b"}, 3, "hello [a b]")
+}
+
+// Go is woken as under Node, and exits with a trace instead of hanging.
+func TestDeadlock(t *testing.T) {
+	testRun(t, "./testdata/deadlock", nil, 2, "goroutine")
+}
@@ -0,0 +1,115 @@
+// Command pirbuild builds a SimplePIR database from a file of records and
+// writes the server state, hint and database info to a snapshot directory
+// (see package cmd/internal/snapshot for the file layout).
+//
+// Usage:
+//
+//	pirbuild -in records.bin -format binary -width 4 -out db/
+//	pirbuild -in records.txt -format lines -d 20 -out db/
+//	pirbuild -in records.csv -format csv -column 2 -header -logq 64 -out db/
+//	pirbuild -in records.csv -format csv -column 1 -key-column 0 -out db/
+//
+// Records are unsigned integers of at most 64 bits, indexed by their
+// position in the file. The record size -d defaults to 8*width bits for
+// binary files, and to the bit length of the largest record otherwise.
+// With -key-column, each CSV row also names its record by a unique key,
+// written to keys.gob for lookups with pirquery -key.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ryanleh/simplepir/cmd/internal/snapshot"
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func main() {
+	in := flag.String("in", "", "input file of records")
+	format := snapshot.RecordFlags()
+	keyColumn := flag.Int("key-column", -1, "0-based column holding unique record keys, for -format csv (-1 for none)")
+	d := flag.Uint64("d", 0, "number of bits per record (0 to infer)")
+	logq := flag.Uint64("logq", 32, "ciphertext modulus bits (32 or 64)")
+	out := flag.String("out", "", "output snapshot directory")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		fail("-in and -out are required")
+	}
+	if *logq != 32 && *logq != 64 {
+		fail("-logq must be 32 or 64")
+	}
+
+	records, err := snapshot.ReadRecordFile(*in, *format)
+	if err != nil {
+		fail(err.Error())
+	}
+	if len(records) == 0 {
+		fail(*in + ": no records")
+	}
+
+	var keys []string
+	if *keyColumn >= 0 {
+		keys, err = snapshot.ReadRecordKeyFile(*in, *format, *keyColumn)
+		if err != nil {
+			fail(err.Error())
+		}
+	}
+
+	if *d == 0 {
+		*d = snapshot.RecordBits(records)
+		if format.Format == snapshot.FormatBinary {
+			*d = 8 * uint64(format.Width)
+		}
+	}
+	if *d > *logq {
+		fail(fmt.Sprintf("%d-bit records need -logq 64", *d))
+	}
+	if *d < 64 {
+		for i, r := range records {
+			if r >= 1<<*d {
+				fail(fmt.Sprintf("record %d does not fit in %d bits", i, *d))
+			}
+		}
+	}
+
+	var info *pir.DBInfo
+	if *logq == 32 {
+		info, err = build[matrix.Elem32](records, *d, *out)
+	} else {
+		info, err = build[matrix.Elem64](records, *d, *out)
+	}
+	if err != nil {
+		fail(err.Error())
+	}
+	if keys != nil {
+		if err := snapshot.WriteKeys(*out, keys); err != nil {
+			fail(err.Error())
+		}
+	}
+
+	fmt.Printf("Wrote %d records of %d bits to %s (L=%d, M=%d, p=%d, squishing=%d)\n",
+		info.Num, info.RowLength, *out, info.L, info.M, info.P(), info.Squishing)
+}
+
+func build[T matrix.Elem](records []uint64, d uint64, dir string) (*pir.DBInfo, error) {
+	vals := make([]T, len(records))
+	for i, r := range records {
+		vals[i] = T(r)
+	}
+
+	db := pir.NewDatabase[T](uint64(len(records)), d, vals)
+	server := pir.NewServerSeed(db, rand.RandomPRGKey())
+	if err := snapshot.Write(dir, server); err != nil {
+		return nil, err
+	}
+	return server.DBInfo(), nil
+}
+
+func fail(msg string) {
+	fmt.Fprintln(os.Stderr, "pirbuild: "+msg)
+	os.Exit(2)
+}
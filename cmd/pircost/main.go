@@ -0,0 +1,86 @@
+// Command pircost estimates the communication and computation costs of
+// SimplePIR on a database of a given size, for capacity planning.
+//
+// Usage:
+//
+//	pircost -log-n 20 -d 8
+//	pircost -n 1000000 -d 2048 -logq 64
+//	pircost -log-n 20 -d 8 -plan budget -budget 10485760
+//
+// With -plan, the database shape is chosen by the layout planner
+// (minhint, online or budget) instead of the default NewDBInfo guess.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ryanleh/simplepir/pir"
+)
+
+func main() {
+	num := flag.Uint64("n", 0, "number of database entries")
+	logN := flag.Uint64("log-n", 0, "log2 of the number of database entries (if -n is not set)")
+	d := flag.Uint64("d", 1, "number of bits per database entry")
+	logq := flag.Uint64("logq", 32, "ciphertext modulus bits (32 or 64)")
+	plan := flag.String("plan", "", "layout planner target: minhint, online or budget")
+	budget := flag.Uint64("budget", 0, "hint budget in bytes, for -plan budget")
+	flag.Parse()
+
+	if *num == 0 {
+		*num = uint64(1) << *logN
+	}
+	if *logq != 32 && *logq != 64 {
+		fail("-logq must be 32 or 64")
+	}
+
+	var info *pir.DBInfo
+	var costs *pir.Costs
+	if *plan == "" {
+		info = pir.NewDBInfo(*logq, *num, *d)
+		costs = info.Costs()
+	} else {
+		target := map[string]pir.LayoutTarget{
+			"minhint": pir.MinimizeHint,
+			"online":  pir.MinimizeOnline,
+			"budget":  pir.HintBudget,
+		}
+		t, ok := target[*plan]
+		if !ok {
+			fail("unknown -plan target " + *plan)
+		}
+
+		layout := pir.PlanLayout(*logq, *num, *d, t, *budget)
+		if layout == nil {
+			fail("no layout fits the hint budget")
+		}
+		info = layout.Info
+		costs = &layout.Costs
+	}
+
+	fmt.Printf("Database: %d entries of %d bits\n", info.Num, info.RowLength)
+	fmt.Printf("\tShape: L=%d M=%d (%d Z_p elems per entry)\n", info.L, info.M, info.Ne)
+	fmt.Printf("\tLWE: n=%d logq=%d p=%d\n", info.Params.N, info.Params.Logq, info.P())
+	fmt.Printf("Offline download (hint): %s\n", size(costs.HintBytes))
+	fmt.Printf("Online upload (query): %s\n", size(costs.QueryBytes))
+	fmt.Printf("Online download (answer): %s\n", size(costs.AnswerBytes))
+	fmt.Printf("Server work per query: %d multiply-adds\n", costs.ServerOps)
+	fmt.Printf("Client preprocessing per query: %d multiply-adds\n", costs.ClientOps)
+}
+
+func size(b uint64) string {
+	switch {
+	case b >= 1<<20:
+		return fmt.Sprintf("%.2f MB", float64(b)/float64(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.2f KB", float64(b)/float64(1<<10))
+	default:
+		return fmt.Sprintf("%d B", b)
+	}
+}
+
+func fail(msg string) {
+	fmt.Fprintln(os.Stderr, "pircost: "+msg)
+	os.Exit(2)
+}
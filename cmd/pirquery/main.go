@@ -0,0 +1,236 @@
+// Command pirquery retrieves one record from a SimplePIR database built by
+// pirbuild, using the same pir.Client as applications do. It is meant for
+// smoke-testing deployments end to end.
+//
+// Usage:
+//
+//	pirquery -db db/ -index 42
+//	pirquery -db db/ -key alice
+//	pirquery -db db/ -index 42 -url http://localhost:8080/answer
+//	pirquery -db db/ -index 42 -verify records.txt -format lines
+//
+// The client files (dbinfo.gob, seed.bin, hint.gob) are read from -db, and
+// -key looks the record up in keys.gob (see pirbuild -key-column). The
+// answer is computed locally from server.gob in the same directory, or, with
+// -url, fetched over HTTP: the gob-encoded query is POSTed and the reply is
+// the gob-encoded answer. -query-out and -answer-out save both messages.
+//
+// To have the query answered some other way, save the client's secret
+// randomness with -secret, then recover the saved answer in a second run:
+//
+//	pirquery -db db/ -index 42 -secret s.key -query-only -query-out q.gob
+//	pirquery -db db/ -index 42 -secret s.key -answer a.gob
+//
+// The secret file is created if it does not exist. It rebuilds the same
+// query, so use a fresh one for each record: queries for different records
+// under the same secret reveal them.
+//
+// With -verify, the recovered record is checked against the plaintext
+// records file (read with the same -format flags as pirbuild), and pirquery
+// exits with status 1 on a mismatch.
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/ryanleh/simplepir/cmd/internal/snapshot"
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func main() {
+	dir := flag.String("db", "", "snapshot directory written by pirbuild")
+	index := flag.Uint64("index", 0, "index of the record to retrieve")
+	key := flag.String("key", "", "key of the record to retrieve, instead of -index")
+	url := flag.String("url", "", "URL answering gob-encoded queries (default: answer locally)")
+	secret := flag.String("secret", "", "file holding the client's secret randomness (created if missing)")
+	queryOnly := flag.Bool("query-only", false, "stop after writing the query, e.g. with -query-out")
+	answerIn := flag.String("answer", "", "file to read a saved gob-encoded answer from, instead of querying")
+	queryOut := flag.String("query-out", "", "file to save the gob-encoded query to")
+	answerOut := flag.String("answer-out", "", "file to save the gob-encoded answer to")
+	verify := flag.String("verify", "", "plaintext records file to check the result against")
+	format := snapshot.RecordFlags()
+	flag.Parse()
+
+	if *dir == "" {
+		fail("-db is required")
+	}
+	if (*queryOnly || *answerIn != "") && *secret == "" {
+		fail("-query-only and -answer need -secret, to rebuild the query later")
+	}
+	info, err := snapshot.ReadInfo(*dir)
+	if err != nil {
+		fail(err.Error())
+	}
+
+	indexSet := false
+	flag.Visit(func(f *flag.Flag) { indexSet = indexSet || f.Name == "index" })
+	if *key != "" {
+		if indexSet {
+			fail("-index and -key are exclusive")
+		}
+		keys, err := snapshot.ReadKeys(*dir)
+		if err != nil {
+			fail(err.Error())
+		}
+		i, ok := keys[*key]
+		if !ok {
+			fail(fmt.Sprintf("no record with key %q", *key))
+		}
+		*index = i
+	}
+	if *index >= info.Num {
+		fail(fmt.Sprintf("index %d out of range: the database has %d records", *index, info.Num))
+	}
+
+	o := options{
+		dir:       *dir,
+		url:       *url,
+		queryOnly: *queryOnly,
+		answerIn:  *answerIn,
+		queryOut:  *queryOut,
+		answerOut: *answerOut,
+	}
+	if *secret != "" {
+		if o.key, err = readSecret(*secret); err != nil {
+			fail(err.Error())
+		}
+	}
+
+	var val uint64
+	if info.Params.Logq == 32 {
+		val, err = query[matrix.Elem32](o, *index)
+	} else {
+		val, err = query[matrix.Elem64](o, *index)
+	}
+	if err != nil {
+		fail(err.Error())
+	}
+	if o.queryOnly {
+		fmt.Printf("query for record %d written\n", *index)
+		return
+	}
+	fmt.Printf("record %d: %d\n", *index, val)
+
+	if *verify != "" {
+		records, err := snapshot.ReadRecordFile(*verify, *format)
+		if err != nil {
+			fail(err.Error())
+		}
+		if uint64(len(records)) != info.Num {
+			fail(fmt.Sprintf("%s has %d records, the database %d", *verify, len(records), info.Num))
+		}
+		if records[*index] != val {
+			fmt.Fprintf(os.Stderr, "pirquery: MISMATCH: expected %d\n", records[*index])
+			os.Exit(1)
+		}
+		fmt.Println("verified")
+	}
+}
+
+type options struct {
+	dir       string
+	url       string
+	key       *rand.PRGKey // nil for fresh randomness
+	queryOnly bool
+	answerIn  string
+	queryOut  string
+	answerOut string
+}
+
+func query[T matrix.Elem](o options, index uint64) (uint64, error) {
+	var client *pir.Client[T]
+	var err error
+	if o.key != nil {
+		client, err = snapshot.ReadClientWithKey[T](o.dir, o.key)
+	} else {
+		client, err = snapshot.ReadClient[T](o.dir)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	secret, q := client.Query(index)
+	if err := save(o.queryOut, q); err != nil {
+		return 0, err
+	}
+	if o.queryOnly {
+		return 0, nil
+	}
+
+	var ans *pir.Answer[T]
+	switch {
+	case o.answerIn != "":
+		ans = new(pir.Answer[T])
+		err = load(o.answerIn, ans)
+	case o.url != "":
+		ans, err = snapshot.PostQuery(o.url, q)
+	default:
+		var server *pir.Server[T]
+		server, err = snapshot.ReadServer[T](o.dir)
+		if err == nil {
+			ans = server.Answer(q)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := save(o.answerOut, ans); err != nil {
+		return 0, err
+	}
+
+	return client.RecoverChecked(secret, ans)
+}
+
+// Reads the secret, or creates it if the file does not exist.
+func readSecret(fn string) (*rand.PRGKey, error) {
+	key := new(rand.PRGKey)
+	buf, err := os.ReadFile(fn)
+	if errors.Is(err, fs.ErrNotExist) {
+		key = rand.RandomPRGKey()
+		return key, os.WriteFile(fn, key[:], 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buf) != len(key) {
+		return nil, fmt.Errorf("%s: expected %d bytes, got %d", fn, len(key), len(buf))
+	}
+	copy(key[:], buf)
+	return key, nil
+}
+
+func save(fn string, val any) error {
+	if fn == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(val); err != nil {
+		return err
+	}
+	return os.WriteFile(fn, buf.Bytes(), 0644)
+}
+
+func load(fn string, val any) error {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(val); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	return nil
+}
+
+func fail(msg string) {
+	fmt.Fprintln(os.Stderr, "pirquery: "+msg)
+	os.Exit(2)
+}
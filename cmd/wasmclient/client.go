@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+// Byte-level client API, wrapped by the JS bindings in main_js.go. The
+// hint, DBInfo, queries and answers are gob-encoded, and the seed of the
+// A matrix is passed as raw bytes (see Server.MatrixA).
+type Session interface {
+	// Generates a fresh secret and precomputes its query, returning a
+	// handle for Query and Recover.
+	Preprocess() uint32
+
+	// Returns the serialized query for the record at 'index'. Each handle
+	// may only be queried once.
+	Query(handle uint32, index uint64) ([]byte, error)
+
+	// Decodes the server's serialized answer. The handle may not be
+	// reused afterwards.
+	Recover(handle uint32, answer []byte) (uint64, error)
+}
+
+type session[T matrix.Elem] struct {
+	client  *pir.Client[T]
+	secrets map[uint32]*pir.Secret[T]
+	next    uint32
+}
+
+func NewSession(hint, dbinfo, seed []byte) (Session, error) {
+	var info pir.DBInfo
+	if err := gob.NewDecoder(bytes.NewReader(dbinfo)).Decode(&info); err != nil {
+		return nil, fmt.Errorf("decoding DBInfo: %w", err)
+	}
+	if info.Params == nil {
+		return nil, fmt.Errorf("DBInfo has no LWE params")
+	}
+
+	var key rand.PRGKey
+	if len(seed) != len(key) {
+		return nil, fmt.Errorf("seed must be %d bytes, got %d", len(key), len(seed))
+	}
+	copy(key[:], seed)
+
+	switch info.Params.Logq {
+	case 32:
+		return newSession[matrix.Elem32](hint, &info, &key)
+	case 64:
+		return newSession[matrix.Elem64](hint, &info, &key)
+	default:
+		return nil, fmt.Errorf("unsupported ciphertext modulus 2^%d", info.Params.Logq)
+	}
+}
+
+func newSession[T matrix.Elem](hint []byte, info *pir.DBInfo, key *rand.PRGKey) (*session[T], error) {
+	h := new(matrix.Matrix[T])
+	if err := h.GobDecode(hint); err != nil {
+		return nil, fmt.Errorf("decoding hint: %w", err)
+	}
+	if h.Rows() != info.L || h.Cols() != info.Params.N {
+		return nil, fmt.Errorf("hint is %d-by-%d, expected %d-by-%d",
+			h.Rows(), h.Cols(), info.L, info.Params.N)
+	}
+
+	return &session[T]{
+		client:  pir.NewClient(h, key, info),
+		secrets: make(map[uint32]*pir.Secret[T]),
+		next:    1,
+	}, nil
+}
+
+func (s *session[T]) Preprocess() uint32 {
+	handle := s.next
+	s.next += 1
+	s.secrets[handle] = s.client.PreprocessQuery()
+	return handle
+}
+
+func (s *session[T]) Query(handle uint32, index uint64) ([]byte, error) {
+	secret, ok := s.secrets[handle]
+	if !ok {
+		return nil, fmt.Errorf("unknown handle %d", handle)
+	}
+	if index >= s.client.GetDBInfo().Num {
+		return nil, fmt.Errorf("index %d out of range", index)
+	}
+
+	query, err := s.client.QueryPreprocessedChecked(index, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(query); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *session[T]) Recover(handle uint32, answer []byte) (uint64, error) {
+	secret, ok := s.secrets[handle]
+	if !ok {
+		return 0, fmt.Errorf("unknown handle %d", handle)
+	}
+
+	var ans pir.Answer[T]
+	if err := gob.NewDecoder(bytes.NewReader(answer)).Decode(&ans); err != nil {
+		return 0, fmt.Errorf("decoding answer: %w", err)
+	}
+	v, err := s.client.RecoverChecked(secret, &ans)
+	if err != nil {
+		return 0, err
+	}
+
+	delete(s.secrets, handle)
+	return v, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+type testSetup struct {
+	hint, dbinfo, seed []byte
+	answer             func([]byte) []byte
+	get                func(uint64) uint64
+}
+
+func newTestSetup[T matrix.Elem](t *testing.T, N, d uint64) *testSetup {
+	prg := rand.NewRandomBufPRG()
+	db := pir.NewDatabaseRandom[T](prg, N, d)
+	server := pir.NewServer(db)
+
+	hint, err := server.Hint().GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info bytes.Buffer
+	if err := gob.NewEncoder(&info).Encode(db.Info); err != nil {
+		t.Fatal(err)
+	}
+
+	return &testSetup{
+		hint:   hint,
+		dbinfo: info.Bytes(),
+		seed:   server.MatrixA()[:],
+		answer: func(q []byte) []byte {
+			var query pir.Query[T]
+			if err := gob.NewDecoder(bytes.NewReader(q)).Decode(&query); err != nil {
+				t.Fatal(err)
+			}
+			var ans bytes.Buffer
+			if err := gob.NewEncoder(&ans).Encode(server.Answer(&query)); err != nil {
+				t.Fatal(err)
+			}
+			return ans.Bytes()
+		},
+		get: db.GetElem,
+	}
+}
+
+func testSession[T matrix.Elem](t *testing.T, N, d uint64) {
+	setup := newTestSetup[T](t, N, d)
+	s, err := NewSession(setup.hint, setup.dbinfo, setup.seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, index := range []uint64{0, N / 3, N - 1} {
+		h := s.Preprocess()
+		q, err := s.Query(h, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := s.Recover(h, setup.answer(q))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != setup.get(index) {
+			t.Fatalf("Got %d instead of %d at index %d", v, setup.get(index), index)
+		}
+
+		if _, err := s.Recover(h, setup.answer(q)); err == nil {
+			t.Fatal("Reused handle")
+		}
+	}
+
+	if _, err := s.Query(s.Preprocess(), N); err == nil {
+		t.Fatal("Queried out of range")
+	}
+
+	// Misusing a handle is an error, not a panic
+	q, err := s.Query(s.Preprocess(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := s.Preprocess()
+	if _, err := s.Recover(h, setup.answer(q)); err != pir.ErrSecretNotQueried {
+		t.Fatalf("Recovered before querying: %v", err)
+	}
+	if _, err := s.Query(h, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Query(h, 1); err != pir.ErrSecretReused {
+		t.Fatalf("Queried the same handle twice: %v", err)
+	}
+	if _, err := NewSession(setup.hint, setup.dbinfo, setup.seed[1:]); err == nil {
+		t.Fatal("Accepted short seed")
+	}
+}
+
+func TestSession32(t *testing.T) {
+	testSession[matrix.Elem32](t, uint64(1<<12), uint64(8))
+}
+
+func TestSession64(t *testing.T) {
+	testSession[matrix.Elem64](t, uint64(1<<10), uint64(32))
+}
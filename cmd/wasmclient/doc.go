@@ -0,0 +1,17 @@
+// Command wasmclient exposes the SimplePIR client to JavaScript, for running
+// queries from a browser.
+//
+// Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o simplepir.wasm ./cmd/wasmclient
+//
+// and load it with the wasm_exec.js shipped in $(go env GOROOT)/lib/wasm
+// (misc/wasm before Go 1.24). The JS API is documented on register in
+// main_js.go.
+//
+// A plain "go test ./cmd/wasmclient" also builds the tests for js/wasm and
+// runs them under the toolchain's wasm_exec.js, without Node (see
+// cmd/internal/wasmexec). This needs a Go distribution that ships
+// wasm_exec.js; otherwise, e.g. for toolchains downloaded through
+// GOTOOLCHAIN, the test is skipped.
+package main
@@ -0,0 +1,141 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"syscall/js"
+)
+
+// Registers the global 'simplepir' object:
+//
+//	const client = simplepir.newClient(hint, dbinfo, seed) // Uint8Arrays
+//	const h = client.preprocess()
+//	const query = client.query(h, index)                   // Uint8Array
+//	const value = client.recover(h, answer)                // BigInt
+//
+// Indices are Numbers, or BigInts beyond Number.MAX_SAFE_INTEGER. Failures,
+// including arguments of the wrong type, are reported by returning an Error
+// instead of throwing.
+func register() {
+	js.Global().Set("simplepir", js.ValueOf(map[string]any{
+		"newClient": js.FuncOf(newClient),
+	}))
+}
+
+func newClient(this js.Value, args []js.Value) any {
+	if len(args) != 3 {
+		return jsError("newClient expects (hint, dbinfo, seed)")
+	}
+	var bufs [3][]byte
+	for i := range bufs {
+		var err error
+		if bufs[i], err = bytesFromJS(args[i]); err != nil {
+			return jsError("newClient: " + err.Error())
+		}
+	}
+
+	s, err := NewSession(bufs[0], bufs[1], bufs[2])
+	if err != nil {
+		return jsError(err.Error())
+	}
+
+	return js.ValueOf(map[string]any{
+		"preprocess": js.FuncOf(func(this js.Value, args []js.Value) any {
+			return s.Preprocess()
+		}),
+		"query": js.FuncOf(func(this js.Value, args []js.Value) any {
+			if len(args) != 2 {
+				return jsError("query expects (handle, index)")
+			}
+			h, err := handleFromJS(args[0])
+			if err != nil {
+				return jsError("query: " + err.Error())
+			}
+			index, err := indexFromJS(args[1])
+			if err != nil {
+				return jsError("query: " + err.Error())
+			}
+			q, err := s.Query(h, index)
+			if err != nil {
+				return jsError(err.Error())
+			}
+			return bytesToJS(q)
+		}),
+		"recover": js.FuncOf(func(this js.Value, args []js.Value) any {
+			if len(args) != 2 {
+				return jsError("recover expects (handle, answer)")
+			}
+			h, err := handleFromJS(args[0])
+			if err != nil {
+				return jsError("recover: " + err.Error())
+			}
+			answer, err := bytesFromJS(args[1])
+			if err != nil {
+				return jsError("recover: " + err.Error())
+			}
+			v, err := s.Recover(h, answer)
+			if err != nil {
+				return jsError(err.Error())
+			}
+			return js.Global().Get("BigInt").Invoke(strconv.FormatUint(v, 10))
+		}),
+	})
+}
+
+func bytesFromJS(v js.Value) ([]byte, error) {
+	if !v.InstanceOf(js.Global().Get("Uint8Array")) {
+		return nil, errors.New("expected a Uint8Array")
+	}
+	b := make([]byte, v.Get("length").Int())
+	js.CopyBytesToGo(b, v)
+	return b, nil
+}
+
+func handleFromJS(v js.Value) (uint32, error) {
+	if !isSafeInteger(v) || v.Float() < 0 || v.Float() > math.MaxUint32 {
+		return 0, errors.New("expected a handle from preprocess")
+	}
+	return uint32(v.Float()), nil
+}
+
+// Numbers are exact up to 2^53; larger indices must be BigInts.
+func indexFromJS(v js.Value) (uint64, error) {
+	if isSafeInteger(v) {
+		if v.Float() < 0 {
+			return 0, errors.New("negative index")
+		}
+		return uint64(v.Float()), nil
+	}
+
+	global := js.Global()
+	if global.Get("Object").Invoke(v).InstanceOf(global.Get("BigInt")) {
+		i, err := strconv.ParseUint(global.Get("String").Invoke(v).String(), 10, 64)
+		if err != nil {
+			return 0, errors.New("index out of range")
+		}
+		return i, nil
+	}
+	return 0, errors.New("index must be an integer Number up to 2^53, or a BigInt")
+}
+
+func isSafeInteger(v js.Value) bool {
+	return js.Global().Get("Number").Call("isSafeInteger", v).Bool()
+}
+
+func bytesToJS(b []byte) js.Value {
+	v := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(v, b)
+	return v
+}
+
+func jsError(msg string) js.Value {
+	return js.Global().Get("Error").New(msg)
+}
+
+func main() {
+	register()
+	select {}
+}
@@ -0,0 +1,97 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strconv"
+	"syscall/js"
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+// Runs a query through the JS API, as a browser would.
+func TestJS(t *testing.T) {
+	register()
+	setup := newTestSetup[matrix.Elem32](t, uint64(1<<10), uint64(8))
+
+	client := js.Global().Get("simplepir").Call("newClient",
+		bytesToJS(setup.hint), bytesToJS(setup.dbinfo), bytesToJS(setup.seed))
+	if isError(client) {
+		t.Fatal(client.Get("message").String())
+	}
+
+	index := uint64(77)
+	h := client.Call("preprocess")
+	q := client.Call("query", h, index)
+	if isError(q) {
+		t.Fatal(q.Get("message").String())
+	}
+
+	v := client.Call("recover", h, bytesToJS(setup.answer(mustBytes(t, q))))
+	if got := js.Global().Get("String").Invoke(v).String(); got != strconv.FormatUint(setup.get(index), 10) {
+		t.Fatalf("Got %s instead of %d", got, setup.get(index))
+	}
+
+	if e := client.Call("recover", h, bytesToJS(setup.answer(mustBytes(t, q)))); !isError(e) {
+		t.Fatal("Reused handle")
+	}
+
+	// Large indices are passed as BigInts, and are range checked
+	h = client.Call("preprocess")
+	big := js.Global().Get("BigInt").Invoke("18446744073709551615")
+	if e := client.Call("query", h, big); !isError(e) {
+		t.Fatal("Accepted an index out of range")
+	}
+	h = client.Call("preprocess")
+	if q := client.Call("query", h, js.Global().Get("BigInt").Invoke(index)); isError(q) {
+		t.Fatal(q.Get("message").String())
+	}
+}
+
+// Arguments of the wrong type are reported as Errors, not panics.
+func TestJSBadArgs(t *testing.T) {
+	register()
+	setup := newTestSetup[matrix.Elem32](t, uint64(1<<10), uint64(8))
+	simplepir := js.Global().Get("simplepir")
+
+	if e := simplepir.Call("newClient", 1, "hint", js.Null()); !isError(e) {
+		t.Fatal("newClient accepted numbers and strings")
+	}
+
+	client := simplepir.Call("newClient",
+		bytesToJS(setup.hint), bytesToJS(setup.dbinfo), bytesToJS(setup.seed))
+	h := client.Call("preprocess")
+	bad := [][]any{
+		{"x", 1},
+		{-1, 1},
+		{1.5, 1},
+		{h, "1"},
+		{h, -1},
+		{h, 0.5},
+		{h, js.Global().Get("Math").Call("pow", 2, 53).Int() + 2},
+		{h, js.Undefined()},
+	}
+	for i, args := range bad {
+		if e := client.Call("query", args...); !isError(e) {
+			t.Fatalf("query %d: accepted %v", i, args)
+		}
+	}
+	for i, args := range [][]any{{h, "answer"}, {"x", js.Global().Get("Uint8Array").New(1)}} {
+		if e := client.Call("recover", args...); !isError(e) {
+			t.Fatalf("recover %d: accepted %v", i, args)
+		}
+	}
+}
+
+func isError(v js.Value) bool {
+	return v.InstanceOf(js.Global().Get("Error"))
+}
+
+func mustBytes(t *testing.T, v js.Value) []byte {
+	b, err := bytesFromJS(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
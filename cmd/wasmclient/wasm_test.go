@@ -0,0 +1,45 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryanleh/simplepir/cmd/internal/wasmexec"
+)
+
+// Builds the tests for js/wasm, and runs them, main_js_test.go included,
+// under wasm_exec.js without Node (see wasmexec).
+func TestWasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the tests for js/wasm")
+	}
+	glue, err := wasmexec.Glue()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	bin := filepath.Join(t.TempDir(), "wasmclient.test")
+	cmd := exec.Command("go", "test", "-c", "-o", bin, ".")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm", "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	wasm, err := os.ReadFile(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"-test.v"}
+	code, err := wasmexec.Run(context.Background(), wasm, glue, args, os.Stdout, os.Stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 0 {
+		t.Fatalf("Tests failed with exit code %d", code)
+	}
+}
@@ -85,6 +85,23 @@ func NewParams(logq uint64, nSamples uint64) *Params {
 	return newParamsFixedP(logq, m, pmod)
 }
 
+// Returns the largest number of samples supported for ciphertext modulus q.
+func MaxSamples(logq uint64) uint64 {
+	options := plaintextModulus32
+	if logq == 64 {
+		options = plaintextModulus64
+	}
+
+	max := uint64(0)
+	for m := range options {
+		if m > max {
+			max = m
+		}
+	}
+
+	return max
+}
+
 func NewParamsFixedP(logq uint64, nSamples uint64, pMod uint64) *Params {
 	if CheckParams(logq, nSamples, pMod) {
 		return newParamsFixedP(logq, nSamples, pMod)
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"math/bits"
 	"os"
 )
 
@@ -41,22 +42,37 @@ func (m Matrix[T]) GobEncode() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// Validates untrusted input: the shape must match the data, which is only
+// allocated as it is read.
 func (m *Matrix[T]) GobDecode(buf []byte) error {
 	b := bytes.NewBuffer(buf)
 	decoder := gob.NewDecoder(b)
-	err1 := decoder.Decode(&m.rows)
-	err2 := decoder.Decode(&m.cols)
 
-	m.data = make([]T, m.rows*m.cols)
-	err3 := decoder.Decode(&m.data)
+	var rows, cols uint64
+	var data []T
+	if err := decoder.Decode(&rows); err != nil {
+		return err
+	}
+	if err := decoder.Decode(&cols); err != nil {
+		return err
+	}
+	if err := decoder.Decode(&data); err != nil {
+		return err
+	}
 
-	if err1 != nil || err2 != nil || err3 != nil {
-		panic("Gob decoding error")
+	if !shapeMatches(rows, cols, uint64(len(data))) {
+		return fmt.Errorf("matrix: %d-by-%d matrix with %d elements", rows, cols, len(data))
 	}
 
+	m.rows, m.cols, m.data = rows, cols, data
 	return nil
 }
 
+func shapeMatches(rows, cols, n uint64) bool {
+	hi, lo := bits.Mul64(rows, cols)
+	return hi == 0 && lo == n
+}
+
 func (m *Matrix[T]) WriteToFile(fn string) error {
 	f, err := os.Create(fn)
 	if err != nil {
@@ -96,19 +112,32 @@ func (m *Matrix[T]) ReadFromFile(fn string) error {
 	return m.ReadFromFileDescriptor(f)
 }
 
+// Preallocates at most readChunk elements, so that a corrupted header
+// cannot exhaust memory.
+const readChunk = 1 << 20
+
 func (m *Matrix[T]) ReadFromFileDescriptor(f *os.File) error {
-	_, err := fmt.Fscanf(f, "%d,%d\n", &m.rows, &m.cols)
+	var rows, cols uint64
+	_, err := fmt.Fscanf(f, "%d,%d\n", &rows, &cols)
 	if err != nil {
 		return err
 	}
 
-	m.data = make([]T, m.rows*m.cols)
-	for i := uint64(0); i < m.rows*m.cols; i++ {
-		_, err = fmt.Fscanf(f, "%d,", &m.data[i])
+	hi, n := bits.Mul64(rows, cols)
+	if hi != 0 {
+		return fmt.Errorf("matrix: %d-by-%d matrix is too large", rows, cols)
+	}
+
+	data := make([]T, 0, min(n, readChunk))
+	for i := uint64(0); i < n; i++ {
+		var elem T
+		_, err = fmt.Fscanf(f, "%d,", &elem)
 		if err != nil {
 			return err
 		}
+		data = append(data, elem)
 	}
 
+	m.rows, m.cols, m.data = rows, cols, data
 	return nil
 }
@@ -0,0 +1,123 @@
+//go:build cgo
+
+#include "matrix.h"
+#include "kernels.h"
+
+static struct {
+  void (*matMulBlock32)(Elem32 *, const Elem32 *, const Elem32 *,
+      size_t, size_t, size_t, size_t);
+  void (*matMulVecPacked32)(Elem32 *, const Elem32 *, const Elem32 *,
+      size_t, size_t, unsigned, unsigned);
+  void (*randMatMul32)(Elem32 *, const uint8_t *, const Elem32 *,
+      size_t, size_t, size_t);
+  void (*matMulBlock64)(Elem64 *, const Elem64 *, const Elem64 *,
+      size_t, size_t, size_t, size_t);
+  void (*matMulVecPacked64)(Elem64 *, const Elem64 *, const Elem64 *,
+      size_t, size_t, unsigned, unsigned);
+  void (*randMatMul64)(Elem64 *, const uint8_t *, const Elem64 *,
+      size_t, size_t, size_t);
+} kernels = {
+  matMulBlock32_scalar, matMulVecPacked32_scalar, randMatMul32_scalar,
+  matMulBlock64_scalar, matMulVecPacked64_scalar, randMatMul64_scalar,
+};
+
+static int current = KERNEL_SCALAR;
+
+#define USE_KERNELS(isa) \
+  kernels.matMulBlock32     = matMulBlock32_##isa; \
+  kernels.matMulVecPacked32 = matMulVecPacked32_##isa; \
+  kernels.randMatMul32      = randMatMul32_##isa; \
+  kernels.matMulBlock64     = matMulBlock64_##isa; \
+  kernels.matMulVecPacked64 = matMulVecPacked64_##isa; \
+  kernels.randMatMul64      = randMatMul64_##isa;
+
+int bestKernel(void)
+{
+#if defined(__x86_64__)
+  __builtin_cpu_init();
+  if (__builtin_cpu_supports("avx512f") && __builtin_cpu_supports("avx512dq"))
+    return KERNEL_AVX512;
+  if (__builtin_cpu_supports("avx2"))
+    return KERNEL_AVX2;
+#endif
+  return KERNEL_SCALAR;
+}
+
+int currentKernel(void)
+{
+  return current;
+}
+
+// Falls back to the best supported kernel if 'kernel' is not supported.
+// Not safe to call concurrently with the matrix operations below.
+int useKernel(int kernel)
+{
+  int best = bestKernel();
+  if (kernel > best || kernel < KERNEL_SCALAR)
+    kernel = best;
+
+  switch (kernel) {
+#if defined(__x86_64__)
+    case KERNEL_AVX512:
+      USE_KERNELS(avx512)
+      break;
+    case KERNEL_AVX2:
+      USE_KERNELS(avx2)
+      break;
+#endif
+    default:
+      kernel = KERNEL_SCALAR;
+      USE_KERNELS(scalar)
+  }
+
+  current = kernel;
+  return kernel;
+}
+
+void matMul32(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aCols, size_t bCols)
+{
+  kernels.matMulBlock32(out, a, b, aRows, aCols, aCols, bCols);
+}
+
+void matMulBlock32(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols)
+{
+  kernels.matMulBlock32(out, a, b, aRows, aStride, bRows, bCols);
+}
+
+void matMulVecPacked32(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  kernels.matMulVecPacked32(out, a, b, aRows, aCols, basis, ratio);
+}
+
+void randMatMul32(Elem32* out, const uint8_t *a, const Elem32 *b,
+    size_t aRows, size_t aCols, size_t bCols)
+{
+  kernels.randMatMul32(out, a, b, aRows, aCols, bCols);
+}
+
+void matMul64(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, size_t bCols)
+{
+  kernels.matMulBlock64(out, a, b, aRows, aCols, aCols, bCols);
+}
+
+void matMulBlock64(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols)
+{
+  kernels.matMulBlock64(out, a, b, aRows, aStride, bRows, bCols);
+}
+
+void matMulVecPacked64(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  kernels.matMulVecPacked64(out, a, b, aRows, aCols, basis, ratio);
+}
+
+void randMatMul64(Elem64* out, const uint8_t *a, const Elem64 *b,
+    size_t aRows, size_t aCols, size_t bCols)
+{
+  kernels.randMatMul64(out, a, b, aRows, aCols, bCols);
+}
@@ -0,0 +1,61 @@
+package matrix
+
+import (
+	"os"
+)
+
+// Implementation used by the matrix kernels (Mul, MulSeededLeft,
+// MulSeededRight and MulVecPacked). By default, the best kernel supported
+// by the CPU is chosen at startup; setting SIMPLEPIR_KERNEL to "scalar",
+// "avx2" or "avx512" overrides this choice. Builds without cgo always use
+// the pure-Go kernels.
+type Kernel int
+
+// Must match KERNEL_* in matrix.h
+const (
+	KernelGo     Kernel = -1
+	KernelScalar Kernel = 0
+	KernelAVX2   Kernel = 1
+	KernelAVX512 Kernel = 2
+)
+
+var kernelNames = map[Kernel]string{
+	KernelGo:     "go",
+	KernelScalar: "scalar",
+	KernelAVX2:   "avx2",
+	KernelAVX512: "avx512",
+}
+
+func (k Kernel) String() string {
+	if name, ok := kernelNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func init() {
+	k := BestKernel()
+	if env := os.Getenv("SIMPLEPIR_KERNEL"); env != "" {
+		for kernel, name := range kernelNames {
+			if name == env {
+				k = kernel
+			}
+		}
+	}
+	UseKernel(k)
+}
+
+func BestKernel() Kernel {
+	return bestKernel()
+}
+
+func CurrentKernel() Kernel {
+	return currentKernel()
+}
+
+// Returns the kernel actually selected, which is BestKernel() if 'k' is
+// not supported by the CPU. Must not be called concurrently with other
+// matrix operations.
+func UseKernel(k Kernel) Kernel {
+	return useKernel(k)
+}
@@ -0,0 +1,83 @@
+package matrix
+
+import (
+	"io"
+	"testing"
+
+	"github.com/ryanleh/simplepir/rand"
+)
+
+// Check that every kernel supported by this CPU, and the pure-Go kernels,
+// match the scalar one.
+func testKernels[U Elem](t *testing.T, r1 uint64, c1 uint64, c2 uint64) {
+	defer UseKernel(CurrentKernel())
+
+	prg := rand.NewRandomBufPRG()
+	key := rand.RandomPRGKey()
+
+	a := Rand[U](prg, r1, c1, 0)
+	b := Rand[U](prg, c1, c2, 0)
+	packed := Rand[U](prg, r1, c1, 1<<a.SquishBasis())
+	packed.Squish()
+	vec := Rand[U](prg, packed.Cols()*packed.SquishRatio(), 1, 0)
+	seeded := func() *MatrixSeeded[U] {
+		return NewSeeded[U]([]IoRandSource{rand.NewBufPRG(rand.NewPRG(key))}, []uint64{r1}, c1)
+	}
+
+	run := func() []*Matrix[U] {
+		return []*Matrix[U]{
+			Mul(a, b),
+			MulSeededLeft(seeded(), b),
+			MulSeededRight(b.Copy(), NewSeeded[U]([]IoRandSource{rand.NewBufPRG(rand.NewPRG(key))}, []uint64{c2}, c1)),
+			MulVecPacked(packed, vec),
+		}
+	}
+
+	UseKernel(KernelScalar)
+	want := run()
+
+	goMul := Zeros[U](r1, c2)
+	goMatMul(goMul.data, a.data, b.data, r1, c1, c2)
+	goLeft := Zeros[U](r1, c2)
+	buf := make([]byte, (U(0).Bitlen()/8)*r1*c1)
+	if _, err := io.ReadFull(seeded().src[0], buf); err != nil {
+		panic("Randomness error")
+	}
+	goRandMatMul(goLeft.data, buf, b.data, r1, c1, c2)
+	goPacked := Zeros[U](r1, 1)
+	goMatMulVecPacked(goPacked.data, packed.data, vec.data, r1, packed.Cols(),
+		packed.SquishBasis(), packed.SquishRatio())
+
+	if !want[0].Equals(goMul) || !want[1].Equals(goLeft) || !want[3].Equals(goPacked) {
+		t.Fatal("Go kernels do not match scalar")
+	}
+
+	for k := KernelScalar; k <= BestKernel(); k++ {
+		if UseKernel(k) != k {
+			t.Fatalf("Could not select kernel %v", k)
+		}
+
+		got := run()
+		for i := range want {
+			if !want[i].Equals(got[i]) {
+				t.Fatalf("Kernel %v: result %d does not match scalar", k, i)
+			}
+		}
+	}
+}
+
+func TestKernels32(t *testing.T) {
+	testKernels[Elem32](t, 37, 1391, 19)
+}
+
+func TestKernels64(t *testing.T) {
+	testKernels[Elem64](t, 37, 1391, 19)
+}
+
+func TestKernelsSmall32(t *testing.T) {
+	testKernels[Elem32](t, 3, 5, 2)
+}
+
+func TestKernelsSmall64(t *testing.T) {
+	testKernels[Elem64](t, 3, 5, 2)
+}
@@ -0,0 +1,39 @@
+// Per-ISA implementations of the kernels in matrix.h. The public entry
+// points dispatch to one of these at runtime (see kernel.c).
+#include <stdint.h>
+#include <stddef.h>
+
+#define DECLARE_KERNELS(isa) \
+  void matMulBlock32_##isa(Elem32 *out, const Elem32 *a, const Elem32 *b, \
+      size_t aRows, size_t aStride, size_t bRows, size_t bCols); \
+  void matMulVecPacked32_##isa(Elem32 *out, const Elem32 *a, const Elem32 *b, \
+      size_t aRows, size_t aCols, unsigned basis, unsigned ratio); \
+  void randMatMul32_##isa(Elem32* out, const uint8_t *a, const Elem32 *b, \
+      size_t aRows, size_t aCols, size_t bCols); \
+  void matMulBlock64_##isa(Elem64 *out, const Elem64 *a, const Elem64 *b, \
+      size_t aRows, size_t aStride, size_t bRows, size_t bCols); \
+  void matMulVecPacked64_##isa(Elem64 *out, const Elem64 *a, const Elem64 *b, \
+      size_t aRows, size_t aCols, unsigned basis, unsigned ratio); \
+  void randMatMul64_##isa(Elem64* out, const uint8_t *a, const Elem64 *b, \
+      size_t aRows, size_t aCols, size_t bCols);
+
+// Expands to a switch returning KERNEL(out, a, b, aRows, aCols, basis,
+// ratio), with compile-time constant basis and ratio for each layout in
+// 'layouts', so that the always-inlined KERNEL is specialized per layout.
+#define SQUISH_CASE(basis, ratio) \
+  case ((basis) << 8) | (ratio): \
+    return KERNEL(out, a, b, aRows, aCols, basis, ratio);
+
+#define SPECIALIZE_PACKED(layouts) \
+  switch ((basis << 8) | ratio) { \
+    layouts(SQUISH_CASE) \
+    default: \
+      return KERNEL(out, a, b, aRows, aCols, basis, ratio); \
+  }
+
+DECLARE_KERNELS(scalar)
+
+#if defined(__x86_64__)
+DECLARE_KERNELS(avx2)
+DECLARE_KERNELS(avx512)
+#endif
@@ -0,0 +1,108 @@
+//go:build cgo
+
+package matrix
+
+// #cgo CFLAGS: -O3
+// #include "matrix.h"
+import "C"
+
+import (
+	"unsafe"
+)
+
+func matMul[T Elem](out, a, b []T, aRows, aCols, bCols uint64) {
+	outPtr := unsafe.Pointer(&out[0])
+	aPtr := unsafe.Pointer(&a[0])
+	bPtr := unsafe.Pointer(&b[0])
+
+	switch T(0).Bitlen() {
+	case 32:
+		C.matMul32((*C.Elem32)(outPtr), (*C.Elem32)(aPtr), (*C.Elem32)(bPtr),
+			C.size_t(aRows), C.size_t(aCols), C.size_t(bCols))
+	case 64:
+		C.matMul64((*C.Elem64)(outPtr), (*C.Elem64)(aPtr), (*C.Elem64)(bPtr),
+			C.size_t(aRows), C.size_t(aCols), C.size_t(bCols))
+	default:
+		panic("Shouldn't get here")
+	}
+}
+
+func matMulBlock[T Elem](out, a, b []T, aRows, aStride, bRows, bCols uint64) {
+	outPtr := unsafe.Pointer(&out[0])
+	aPtr := unsafe.Pointer(&a[0])
+	bPtr := unsafe.Pointer(&b[0])
+
+	switch T(0).Bitlen() {
+	case 32:
+		C.matMulBlock32((*C.Elem32)(outPtr), (*C.Elem32)(aPtr), (*C.Elem32)(bPtr),
+			C.size_t(aRows), C.size_t(aStride), C.size_t(bRows), C.size_t(bCols))
+	case 64:
+		C.matMulBlock64((*C.Elem64)(outPtr), (*C.Elem64)(aPtr), (*C.Elem64)(bPtr),
+			C.size_t(aRows), C.size_t(aStride), C.size_t(bRows), C.size_t(bCols))
+	default:
+		panic("Shouldn't get here")
+	}
+}
+
+func matMulVec[T Elem](out, a, b []T, aRows, aCols uint64) {
+	outPtr := unsafe.Pointer(&out[0])
+	aPtr := unsafe.Pointer(&a[0])
+	bPtr := unsafe.Pointer(&b[0])
+
+	switch T(0).Bitlen() {
+	case 32:
+		C.matMulVec32((*C.Elem32)(outPtr), (*C.Elem32)(aPtr), (*C.Elem32)(bPtr),
+			C.size_t(aRows), C.size_t(aCols))
+	case 64:
+		C.matMulVec64((*C.Elem64)(outPtr), (*C.Elem64)(aPtr), (*C.Elem64)(bPtr),
+			C.size_t(aRows), C.size_t(aCols))
+	default:
+		panic("Shouldn't get here")
+	}
+}
+
+func matMulVecPacked[T Elem](out, a, b []T, aRows, aCols, basis, ratio uint64) {
+	outPtr := unsafe.Pointer(&out[0])
+	aPtr := unsafe.Pointer(&a[0])
+	bPtr := unsafe.Pointer(&b[0])
+
+	switch T(0).Bitlen() {
+	case 32:
+		C.matMulVecPacked32((*C.Elem32)(outPtr), (*C.Elem32)(aPtr), (*C.Elem32)(bPtr),
+			C.size_t(aRows), C.size_t(aCols), C.unsigned(basis), C.unsigned(ratio))
+	case 64:
+		C.matMulVecPacked64((*C.Elem64)(outPtr), (*C.Elem64)(aPtr), (*C.Elem64)(bPtr),
+			C.size_t(aRows), C.size_t(aCols), C.unsigned(basis), C.unsigned(ratio))
+	default:
+		panic("Shouldn't get here")
+	}
+}
+
+func randMatMul[T Elem](out []T, a []byte, b []T, aRows, aCols, bCols uint64) {
+	outPtr := unsafe.Pointer(&out[0])
+	aPtr := unsafe.Pointer(&a[0])
+	bPtr := unsafe.Pointer(&b[0])
+
+	switch T(0).Bitlen() {
+	case 32:
+		C.randMatMul32((*C.Elem32)(outPtr), (*C.uint8_t)(aPtr), (*C.Elem32)(bPtr),
+			C.size_t(aRows), C.size_t(aCols), C.size_t(bCols))
+	case 64:
+		C.randMatMul64((*C.Elem64)(outPtr), (*C.uint8_t)(aPtr), (*C.Elem64)(bPtr),
+			C.size_t(aRows), C.size_t(aCols), C.size_t(bCols))
+	default:
+		panic("Shouldn't get here")
+	}
+}
+
+func bestKernel() Kernel {
+	return Kernel(C.bestKernel())
+}
+
+func currentKernel() Kernel {
+	return Kernel(C.currentKernel())
+}
+
+func useKernel(k Kernel) Kernel {
+	return Kernel(C.useKernel(C.int(k)))
+}
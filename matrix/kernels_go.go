@@ -0,0 +1,79 @@
+package matrix
+
+import (
+	"encoding/binary"
+)
+
+// Pure-Go versions of the C kernels in matrix32.c and matrix64.c. They are
+// used when building without cgo (see kernels_purego.go), and always
+// compiled so that they can be tested against the C versions.
+
+func goMatMul[T Elem](out, a, b []T, aRows, aCols, bCols uint64) {
+	goMatMulBlock(out, a, b, aRows, aCols, aCols, bCols)
+}
+
+func goMatMulBlock[T Elem](out, a, b []T, aRows, aStride, bRows, bCols uint64) {
+	for i := uint64(0); i < aRows; i++ {
+		row := out[bCols*i : bCols*(i+1)]
+		for k := uint64(0); k < bRows; k++ {
+			val := a[aStride*i+k]
+			bRow := b[bCols*k : bCols*(k+1)]
+			for j := range row {
+				row[j] += val * bRow[j]
+			}
+		}
+	}
+}
+
+func goMatMulVec[T Elem](out, a, b []T, aRows, aCols uint64) {
+	b = b[:aCols]
+	for i := uint64(0); i < aRows; i++ {
+		tmp := T(0)
+		for j, val := range a[aCols*i : aCols*(i+1)] {
+			tmp += val * b[j]
+		}
+		out[i] = tmp
+	}
+}
+
+func goMatMulVecPacked[T Elem](out, a, b []T, aRows, aCols, basis, ratio uint64) {
+	mask := T((1 << basis) - 1)
+	b = b[:aCols*ratio]
+	for i := uint64(0); i < aRows; i++ {
+		tmp := T(0)
+		index := uint64(0)
+		for _, db := range a[aCols*i : aCols*(i+1)] {
+			for k := uint64(0); k < ratio; k++ {
+				tmp += ((db >> (k * basis)) & mask) * b[index]
+				index += 1
+			}
+		}
+		out[i] += tmp
+	}
+}
+
+func goRandMatMul[T Elem](out []T, a []byte, b []T, aRows, aCols, bCols uint64) {
+	elemSz := T(0).Bitlen() / 8
+	start := uint64(0)
+
+	for i := uint64(0); i < aRows; i++ {
+		row := out[bCols*i : bCols*(i+1)]
+		for j := uint64(0); j < aCols; j++ {
+			var val T
+			switch elemSz {
+			case 4:
+				val = T(binary.LittleEndian.Uint32(a[start:]))
+			case 8:
+				val = T(binary.LittleEndian.Uint64(a[start:]))
+			default:
+				panic("Shouldn't get here")
+			}
+			start += elemSz
+
+			bRow := b[bCols*j : bCols*(j+1)]
+			for k := range row {
+				row[k] += val * bRow[k]
+			}
+		}
+	}
+}
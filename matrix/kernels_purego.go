@@ -0,0 +1,35 @@
+//go:build !cgo
+
+package matrix
+
+func matMul[T Elem](out, a, b []T, aRows, aCols, bCols uint64) {
+	goMatMul(out, a, b, aRows, aCols, bCols)
+}
+
+func matMulBlock[T Elem](out, a, b []T, aRows, aStride, bRows, bCols uint64) {
+	goMatMulBlock(out, a, b, aRows, aStride, bRows, bCols)
+}
+
+func matMulVec[T Elem](out, a, b []T, aRows, aCols uint64) {
+	goMatMulVec(out, a, b, aRows, aCols)
+}
+
+func matMulVecPacked[T Elem](out, a, b []T, aRows, aCols, basis, ratio uint64) {
+	goMatMulVecPacked(out, a, b, aRows, aCols, basis, ratio)
+}
+
+func randMatMul[T Elem](out []T, a []byte, b []T, aRows, aCols, bCols uint64) {
+	goRandMatMul(out, a, b, aRows, aCols, bCols)
+}
+
+func bestKernel() Kernel {
+	return KernelGo
+}
+
+func currentKernel() Kernel {
+	return KernelGo
+}
+
+func useKernel(k Kernel) Kernel {
+	return KernelGo
+}
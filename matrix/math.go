@@ -1,15 +1,15 @@
 package matrix
 
-// #cgo CFLAGS: -O3 -march=native
-// #include "matrix.h"
-import "C"
-
 import (
 	"fmt"
 	"io"
-	"unsafe"
+	"runtime"
+	"sync"
 )
 
+// Size of each block of rows expanded by MulSeededRight.
+var seededBlockBytes = uint64(1 << 24)
+
 func (a *Matrix[T]) Add(b *Matrix[T]) {
 	if (a.cols != b.cols) || (a.rows != b.rows) {
 		fmt.Printf("%d-by-%d vs. %d-by-%d\n", a.rows, a.cols, b.rows, b.cols)
@@ -86,23 +86,7 @@ func Mul[T Elem](a *Matrix[T], b *Matrix[T]) *Matrix[T] {
 	}
 
 	out := Zeros[T](a.rows, b.cols)
-
-	arows := C.size_t(a.rows)
-	acols := C.size_t(a.cols)
-	bcols := C.size_t(b.cols)
-
-	outPtr := unsafe.Pointer(&out.data[0])
-	aPtr := unsafe.Pointer(&a.data[0])
-	bPtr := unsafe.Pointer(&b.data[0])
-
-	switch T(0).Bitlen() {
-	case 32:
-		C.matMul32((*Elem32)(outPtr), (*Elem32)(aPtr), (*Elem32)(bPtr), arows, acols, bcols)
-	case 64:
-		C.matMul64((*Elem64)(outPtr), (*Elem64)(aPtr), (*Elem64)(bPtr), arows, acols, bcols)
-	default:
-		panic("Shouldn't get here")
-	}
+	matMul(out.data, a.data, b.data, a.rows, a.cols, b.cols)
 
 	return out
 }
@@ -126,28 +110,18 @@ func MulSeededLeft[T Elem](a *MatrixSeeded[T], b *Matrix[T]) *Matrix[T] {
 
 	elemSz := T(0).Bitlen() / 8
 	curRows := uint64(0)
-	bPtr := unsafe.Pointer(&b.data[0])
 
 	ch := make(chan bool)
 	for i := range a.rows {
 		go func(it int, curRowsIn uint64) {
 			buf := make([]byte, elemSz*a.cols*a.rows[it])
-			bufPtr := unsafe.Pointer(&buf[0])
-			outPtr := unsafe.Pointer(&out.data[curRowsIn*b.cols])
 
 			_, err := io.ReadFull(a.src[it], buf)
 			if err != nil {
 				panic("Randomness error")
 			}
 
-			switch T(0).Bitlen() {
-			case 32:
-				C.randMatMul32((*Elem32)(outPtr), (*C.uint8_t)(bufPtr), (*Elem32)(bPtr), C.size_t(a.rows[it]), C.size_t(a.cols), C.size_t(b.cols))
-			case 64:
-				C.randMatMul64((*Elem64)(outPtr), (*C.uint8_t)(bufPtr), (*Elem64)(bPtr), C.size_t(a.rows[it]), C.size_t(a.cols), C.size_t(b.cols))
-			default:
-				panic("Shouldn't get here")
-			}
+			randMatMul(out.data[curRowsIn*b.cols:], buf, b.data, a.rows[it], a.cols, b.cols)
 
 			ch <- true
 		}(i, curRows)
@@ -164,6 +138,81 @@ func MulSeededLeft[T Elem](a *MatrixSeeded[T], b *Matrix[T]) *Matrix[T] {
 	return out
 }
 
+// Computes a * b, expanding b from its seeds one block of rows at a time,
+// so that b is never held in memory in full.
+func MulSeededRight[T Elem](a *Matrix[T], b *MatrixSeeded[T]) *Matrix[T] {
+	if len(b.src) != len(b.rows) {
+		panic("Bad input")
+	}
+
+	bRows := uint64(0)
+	for _, rows := range b.rows {
+		bRows += rows
+	}
+
+	if a.cols != bRows {
+		fmt.Printf("%d-by-%d vs. %d-by-%d\n", a.rows, a.cols, bRows, b.cols)
+		panic("Dimension mismatch")
+	}
+
+	out := Zeros[T](a.rows, b.cols)
+	if out.Size() == 0 || a.Size() == 0 {
+		return out
+	}
+
+	elemSz := T(0).Bitlen() / 8
+	blockRows := seededBlockBytes / (elemSz * b.cols)
+	if blockRows == 0 {
+		blockRows = 1
+	}
+
+	offset := uint64(0)
+	for it := range b.src {
+		for done := uint64(0); done < b.rows[it]; {
+			num := blockRows
+			if num > b.rows[it]-done {
+				num = b.rows[it] - done
+			}
+
+			// Reads the same bytes, in the same order, as Rand on the full matrix
+			block := Rand[T](b.src[it], num, b.cols, 0)
+			mulBlock(out, a, offset, block)
+
+			done += num
+			offset += num
+		}
+	}
+
+	return out
+}
+
+// Computes out += a[:, offset:offset+b.rows] * b, splitting the rows of a
+// across threads.
+func mulBlock[T Elem](out, a *Matrix[T], offset uint64, b *Matrix[T]) {
+	threads := uint64(runtime.NumCPU())
+	if threads > a.rows {
+		threads = a.rows
+	}
+	perThread := (a.rows + threads - 1) / threads
+
+	var wg sync.WaitGroup
+	for start := uint64(0); start < a.rows; start += perThread {
+		rows := perThread
+		if rows > a.rows-start {
+			rows = a.rows - start
+		}
+
+		wg.Add(1)
+		go func(start, rows uint64) {
+			defer wg.Done()
+
+			matMulBlock(out.data[start*out.cols:], a.data[start*a.cols+offset:], b.data,
+				rows, a.cols, b.rows, b.cols)
+		}(start, rows)
+	}
+	wg.Wait()
+}
+
 func MulVec[T Elem](a *Matrix[T], b *Matrix[T]) *Matrix[T] {
 	if (a.cols != b.rows) && (a.cols+1 != b.rows) && (a.cols+2 != b.rows) { // do not require exact match because of DB compression
 		fmt.Printf("%d-by-%d vs. %d-by-%d\n", a.rows, a.cols, b.rows, b.cols)
@@ -174,29 +223,22 @@ func MulVec[T Elem](a *Matrix[T], b *Matrix[T]) *Matrix[T] {
 	}
 
 	out := New[T](a.rows, 1)
-	arows := C.size_t(a.rows)
-	acols := C.size_t(a.cols)
-
-	outPtr := unsafe.Pointer(&out.data[0])
-	aPtr := unsafe.Pointer(&a.data[0])
-	bPtr := unsafe.Pointer(&b.data[0])
-
-	switch T(0).Bitlen() {
-	case 32:
-		C.matMulVec32((*Elem32)(outPtr), (*Elem32)(aPtr), (*Elem32)(bPtr), arows, acols)
-	case 64:
-		C.matMulVec64((*Elem64)(outPtr), (*Elem64)(aPtr), (*Elem64)(bPtr), arows, acols)
-	default:
-		panic("Shouldn't get here")
-	}
+	matMulVec(out.data, a.data, b.data, a.rows, a.cols)
 
 	return out
 }
 
+// Multiplies a matrix squished with the default parameters by a vector.
 func MulVecPacked[T Elem](a *Matrix[T], b *Matrix[T]) *Matrix[T] {
-	if a.cols*a.SquishRatio() != b.rows {
+	return MulVecPackedWith(a, b, SquishParams{a.SquishBasis(), a.SquishRatio()})
+}
+
+// Multiplies a matrix squished with SquishWith(params) by a vector.
+func MulVecPackedWith[T Elem](a *Matrix[T], b *Matrix[T], params SquishParams) *Matrix[T] {
+	params.check(T(0).Bitlen())
+	if a.cols*params.Ratio != b.rows {
 		fmt.Printf("%d-by-%d vs. %d-by-%d\n", a.rows, a.cols, b.rows, b.cols)
-		fmt.Printf("Want %v == %v", a.cols*a.SquishRatio(), b.rows)
+		fmt.Printf("Want %v == %v", a.cols*params.Ratio, b.rows)
 		panic("Dimension mismatch")
 	}
 	if b.cols != 1 {
@@ -204,23 +246,64 @@ func MulVecPacked[T Elem](a *Matrix[T], b *Matrix[T]) *Matrix[T] {
 	}
 
 	out := New[T](a.rows+8, 1)
-	arows := C.size_t(a.rows)
-	acols := C.size_t(a.cols)
+	matMulVecPacked(out.data, a.data, b.data, a.rows, a.cols, params.Basis, params.Ratio)
+
+	out.DropLastrows(8)
+
+	return out
+}
+
+// Bytes of 'a' per tile in MulPackedWith, small enough for the tile to stay
+// in cache while it is multiplied by every column.
+const packedTileBytes = 1 << 20
 
-	outPtr := unsafe.Pointer(&out.data[0])
-	aPtr := unsafe.Pointer(&a.data[0])
-	bPtr := unsafe.Pointer(&b.data[0])
+// Multiplies a matrix squished with SquishWith(params) by a matrix, e.g. a
+// batch of query vectors as columns, reading 'a' from memory only once.
+func MulPackedWith[T Elem](a *Matrix[T], b *Matrix[T], params SquishParams) *Matrix[T] {
+	params.check(T(0).Bitlen())
+	if a.cols*params.Ratio != b.rows {
+		fmt.Printf("%d-by-%d vs. %d-by-%d\n", a.rows, a.cols, b.rows, b.cols)
+		fmt.Printf("Want %v == %v", a.cols*params.Ratio, b.rows)
+		panic("Dimension mismatch")
+	}
+
+	out := Zeros[T](a.rows, b.cols)
+	if out.Size() == 0 || a.cols == 0 {
+		return out
+	}
 
-	switch T(0).Bitlen() {
-	case 32:
-		C.matMulVecPacked32((*Elem32)(outPtr), (*Elem32)(aPtr), (*Elem32)(bPtr), arows, acols)
-	case 64:
-		C.matMulVecPacked64((*Elem64)(outPtr), (*Elem64)(aPtr), (*Elem64)(bPtr), arows, acols)
-	default:
-		panic("Shouldn't get here")
+	vecs := make([][]T, b.cols)
+	for c := range vecs {
+		vecs[c] = make([]T, b.rows)
+		for r := uint64(0); r < b.rows; r++ {
+			vecs[c][r] = b.data[r*b.cols+uint64(c)]
+		}
 	}
 
-	out.DropLastrows(8)
+	// Whole blocks of 8 rows, as processed by the kernels
+	tileRows := packedTileBytes / (a.cols * (T(0).Bitlen() / 8)) / 8 * 8
+	if tileRows == 0 {
+		tileRows = 8
+	}
+
+	tmp := make([]T, tileRows+8) // the kernels may write past the last row
+	for start := uint64(0); start < a.rows; start += tileRows {
+		rows := tileRows
+		if rows > a.rows-start {
+			rows = a.rows - start
+		}
+		tile := a.data[start*a.cols : (start+rows)*a.cols]
+
+		for c, vec := range vecs {
+			for i := range tmp {
+				tmp[i] = 0
+			}
+			matMulVecPacked(tmp, tile, vec, rows, a.cols, params.Basis, params.Ratio)
+			for r := uint64(0); r < rows; r++ {
+				out.data[(start+r)*b.cols+uint64(c)] = tmp[r]
+			}
+		}
+	}
 
 	return out
 }
@@ -1,9 +1,5 @@
 package matrix
 
-// #cgo CFLAGS: -O3 -march=native
-// #include "matrix.h"
-import "C"
-
 import (
 	"crypto/rand"
 	"encoding/binary"
@@ -15,8 +11,8 @@ import (
 	"github.com/ryanleh/simplepir/lwe"
 )
 
-type Elem32 = C.Elem32
-type Elem64 = C.Elem64
+type Elem32 uint32
+type Elem64 uint64
 
 type Elem interface {
 	Elem32 | Elem64
@@ -1,27 +1,35 @@
 #include <stdint.h>
 #include <stddef.h>
 
-// Hard-coded, to allow for compiler optimizations:
-#define COMPRESSION_32 3
-#define BASIS_32       10
-#define BASIS2_32      BASIS_32*2
-#define MASK_32        (1<<BASIS_32)-1
-
-#define COMPRESSION_64 2
-#define BASIS_64       30
-#define MASK_64        (1<<BASIS_64)-1
+// Squishing layouts X(basis, ratio) that get a specialized kernel, to allow
+// for compiler optimizations. Other layouts fall back to a generic kernel.
+// Must match squishLayouts* in squish.go
+#define SQUISH_LAYOUTS_32(X) X(8, 4) X(10, 3) X(16, 2)
+#define SQUISH_LAYOUTS_64(X) X(8, 8) X(16, 4) X(21, 3) X(30, 2) X(32, 2)
 
 typedef uint32_t Elem32;
 typedef uint64_t Elem64;
 
+// Kernel implementations, selected at runtime based on the CPU
+#define KERNEL_SCALAR 0
+#define KERNEL_AVX2   1
+#define KERNEL_AVX512 2
+
+int bestKernel(void);
+int currentKernel(void);
+int useKernel(int kernel);
+
 void matMul32(Elem32 *out, const Elem32 *a, const Elem32 *b,
     size_t aRows, size_t aCols, size_t bCols);
 
+void matMulBlock32(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols);
+
 void matMulVec32(Elem32 *out, const Elem32 *a, const Elem32 *b,
     size_t aRows, size_t aCols);
 
 void matMulVecPacked32(Elem32 *out, const Elem32 *a, const Elem32 *b,
-    size_t aRows, size_t aCols);
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio);
 
 void randMatMul32(Elem32* out, const uint8_t *a, const Elem32 *b,
     size_t aRows, size_t aCols, size_t bCols);
@@ -29,11 +37,14 @@ void randMatMul32(Elem32* out, const uint8_t *a, const Elem32 *b,
 void matMul64(Elem64 *out, const Elem64 *a, const Elem64 *b,
     size_t aRows, size_t aCols, size_t bCols);
 
+void matMulBlock64(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols);
+
 void matMulVec64(Elem64 *out, const Elem64 *a, const Elem64 *b,
     size_t aRows, size_t aCols);
 
 void matMulVecPacked64(Elem64 *out, const Elem64 *a, const Elem64 *b,
-    size_t aRows, size_t aCols);
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio);
 
 void randMatMul64(Elem64* out, const uint8_t *a, const Elem64 *b,
     size_t aRows, size_t aCols, size_t bCols);
@@ -1,13 +1,16 @@
+//go:build cgo
+
 #include "matrix.h"
+#include "kernels.h"
 #include <stdio.h>
 
-void matMul64(Elem64 *out, const Elem64 *a, const Elem64 *b,
-    size_t aRows, size_t aCols, size_t bCols)
+void matMulBlock64_scalar(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols)
 {
   for (size_t i = 0; i < aRows; i++) {
-    for (size_t k = 0; k < aCols; k++) {
+    for (size_t k = 0; k < bRows; k++) {
       for (size_t j = 0; j < bCols; j++) {
-        out[bCols*i + j] += a[aCols*i + k]*b[bCols*k + j];
+        out[bCols*i + j] += a[aStride*i + k]*b[bCols*k + j];
       }
     }
   }
@@ -26,7 +29,7 @@ void matMulVec64(Elem64 *out, const Elem64 *a, const Elem64 *b,
   }
 }
 
-void randMatMul64(Elem64* out, const uint8_t *a, const Elem64 *b,
+void randMatMul64_scalar(Elem64* out, const uint8_t *a, const Elem64 *b,
     size_t aRows, size_t aCols, size_t bCols)
 {
   Elem64 val;
@@ -52,83 +55,60 @@ void randMatMul64(Elem64* out, const uint8_t *a, const Elem64 *b,
   }
 }
 
-void matMulVecPacked64(Elem64 *out, const Elem64 *a, const Elem64 *b,
-    size_t aRows, size_t aCols)
+// Each packed word of 'a' holds 'ratio' values of 'basis' bits. Processes
+// eight rows at a time.
+static inline __attribute__((always_inline)) int packed64(Elem64 *out,
+    const Elem64 *a, const Elem64 *b, size_t aRows, size_t aCols,
+    const unsigned basis, const unsigned ratio)
 {
-  Elem64 db, db2, db3, db4, db5, db6, db7, db8;
-  Elem64 val, val2, val3, val4, val5, val6, val7, val8;
-  Elem64 tmp, tmp2, tmp3, tmp4, tmp5, tmp6, tmp7, tmp8;
-  size_t index = 0;
-  size_t index2;
+  const Elem64 mask = (((Elem64)1) << basis) - 1;
+  Elem64 tmp[8];
 
-  for (size_t i = 0; i < aRows; i += 8) {
-    tmp  = 0;
-    tmp2 = 0;
-    tmp3 = 0;
-    tmp4 = 0;
-    tmp5 = 0;
-    tmp6 = 0;
-    tmp7 = 0;
-    tmp8 = 0;
+  size_t i = 0;
+  for (; i + 8 <= aRows; i += 8) {
+    const Elem64 *rows = a + aCols*i;
+    for (int r = 0; r < 8; r++) {
+      tmp[r] = 0;
+    }
 
-    index2 = 0;
     for (size_t j = 0; j < aCols; j++) {
-      db  = a[index];
-      db2 = a[index+1*aCols];
-      db3 = a[index+2*aCols];
-      db4 = a[index+3*aCols];
-      db5 = a[index+4*aCols];
-      db6 = a[index+5*aCols];
-      db7 = a[index+6*aCols];
-      db8 = a[index+7*aCols];
-
-      val  = db & MASK_64;
-      val2 = db2 & MASK_64;
-      val3 = db3 & MASK_64;
-      val4 = db4 & MASK_64;
-      val5 = db5 & MASK_64;
-      val6 = db6 & MASK_64;
-      val7 = db7 & MASK_64;
-      val8 = db8 & MASK_64;
-      tmp  += val*b[index2];
-      tmp2 += val2*b[index2];
-      tmp3 += val3*b[index2];
-      tmp4 += val4*b[index2];
-      tmp5 += val5*b[index2];
-      tmp6 += val6*b[index2];
-      tmp7 += val7*b[index2];
-      tmp8 += val8*b[index2];
-      index2 += 1;
+      for (unsigned k = 0; k < ratio; k++) {
+        Elem64 q = b[j*ratio + k];
+        for (int r = 0; r < 8; r++) {
+          tmp[r] += ((rows[aCols*r + j] >> (basis*k)) & mask) * q;
+        }
+      }
+    }
 
-      val  = (db >> BASIS_64) & MASK_64;
-      val2 = (db2 >> BASIS_64) & MASK_64;
-      val3 = (db3 >> BASIS_64) & MASK_64;
-      val4 = (db4 >> BASIS_64) & MASK_64;
-      val5 = (db5 >> BASIS_64) & MASK_64;
-      val6 = (db6 >> BASIS_64) & MASK_64;
-      val7 = (db7 >> BASIS_64) & MASK_64;
-      val8 = (db8 >> BASIS_64) & MASK_64;
-      tmp  += val*b[index2];
-      tmp2 += val2*b[index2];
-      tmp3 += val3*b[index2];
-      tmp4 += val4*b[index2];
-      tmp5 += val5*b[index2];
-      tmp6 += val6*b[index2];
-      tmp7 += val7*b[index2];
-      tmp8 += val8*b[index2];
-      index2 += 1;
+    for (int r = 0; r < 8; r++) {
+      out[i + r] += tmp[r];
+    }
+  }
 
-      index += 1;
+  for (; i < aRows; i++) {
+    const Elem64 *row = a + aCols*i;
+    Elem64 acc = 0;
+    for (size_t j = 0; j < aCols; j++) {
+      for (unsigned k = 0; k < ratio; k++) {
+        acc += ((row[j] >> (basis*k)) & mask) * b[j*ratio + k];
+      }
     }
-    out[i]   += tmp;
-    out[i+1] += tmp2;
-    out[i+2] += tmp3;
-    out[i+3] += tmp4;
-    out[i+4] += tmp5;
-    out[i+5] += tmp6;
-    out[i+6] += tmp7;
-    out[i+7] += tmp8;
-    index += aCols*7;
+    out[i] += acc;
   }
+
+  return 1;
+}
+
+#define KERNEL packed64
+static int packed64_layout(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  SPECIALIZE_PACKED(SQUISH_LAYOUTS_64)
 }
+#undef KERNEL
 
+void matMulVecPacked64_scalar(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  packed64_layout(out, a, b, aRows, aCols, basis, ratio);
+}
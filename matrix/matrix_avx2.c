@@ -0,0 +1,245 @@
+//go:build cgo
+
+#include "matrix.h"
+#include "kernels.h"
+
+#if defined(__x86_64__)
+#include <immintrin.h>
+#include <stdlib.h>
+
+#define AVX2 __attribute__((target("avx2")))
+#define AVX2_INLINE static inline __attribute__((target("avx2"), always_inline))
+
+// out[j] += s * b[j]
+AVX2_INLINE void axpy32(Elem32 *out, Elem32 s, const Elem32 *b, size_t n)
+{
+  __m256i vs = _mm256_set1_epi32((int)s);
+  size_t j = 0;
+  for (; j + 8 <= n; j += 8) {
+    __m256i o = _mm256_loadu_si256((const __m256i *)(out + j));
+    __m256i v = _mm256_loadu_si256((const __m256i *)(b + j));
+    o = _mm256_add_epi32(o, _mm256_mullo_epi32(vs, v));
+    _mm256_storeu_si256((__m256i *)(out + j), o);
+  }
+  for (; j < n; j++) {
+    out[j] += s*b[j];
+  }
+}
+
+// AVX2 has no 64-bit multiply, so assemble it from 32-bit halves.
+AVX2_INLINE __m256i mul64(__m256i a, __m256i b)
+{
+  __m256i lo = _mm256_mul_epu32(a, b);
+  __m256i cross = _mm256_add_epi64(
+      _mm256_mul_epu32(_mm256_srli_epi64(a, 32), b),
+      _mm256_mul_epu32(a, _mm256_srli_epi64(b, 32)));
+  return _mm256_add_epi64(lo, _mm256_slli_epi64(cross, 32));
+}
+
+AVX2_INLINE void axpy64(Elem64 *out, Elem64 s, const Elem64 *b, size_t n)
+{
+  __m256i vs = _mm256_set1_epi64x((long long)s);
+  size_t j = 0;
+  for (; j + 4 <= n; j += 4) {
+    __m256i o = _mm256_loadu_si256((const __m256i *)(out + j));
+    __m256i v = _mm256_loadu_si256((const __m256i *)(b + j));
+    o = _mm256_add_epi64(o, mul64(vs, v));
+    _mm256_storeu_si256((__m256i *)(out + j), o);
+  }
+  for (; j < n; j++) {
+    out[j] += s*b[j];
+  }
+}
+
+AVX2 void matMulBlock32_avx2(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols)
+{
+  for (size_t i = 0; i < aRows; i++) {
+    for (size_t k = 0; k < bRows; k++) {
+      axpy32(out + bCols*i, a[aStride*i + k], b + bCols*k, bCols);
+    }
+  }
+}
+
+AVX2 void matMulBlock64_avx2(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols)
+{
+  for (size_t i = 0; i < aRows; i++) {
+    for (size_t k = 0; k < bRows; k++) {
+      axpy64(out + bCols*i, a[aStride*i + k], b + bCols*k, bCols);
+    }
+  }
+}
+
+AVX2 void randMatMul32_avx2(Elem32* out, const uint8_t *a, const Elem32 *b,
+    size_t aRows, size_t aCols, size_t bCols)
+{
+  Elem32 val;
+  Elem64 start = 0;
+
+  for (size_t i = 0; i < aRows; i++) {
+    for (size_t j = 0; j < aCols; j++) {
+      val = ((Elem32)a[start+0]) |
+	    (((Elem32)a[start+1]) << 8) |
+	    (((Elem32)a[start+2]) << 16) |
+	    (((Elem32)a[start+3]) << 24);
+
+      start += 4;
+      axpy32(out + bCols*i, val, b + bCols*j, bCols);
+    }
+  }
+}
+
+AVX2 void randMatMul64_avx2(Elem64* out, const uint8_t *a, const Elem64 *b,
+    size_t aRows, size_t aCols, size_t bCols)
+{
+  Elem64 val;
+  Elem64 start = 0;
+
+  for (size_t i = 0; i < aRows; i++) {
+    for (size_t j = 0; j < aCols; j++) {
+      val = ((Elem64)a[start+0]) |
+	    (((Elem64)a[start+1]) << 8) |
+	    (((Elem64)a[start+2]) << 16) |
+	    (((Elem64)a[start+3]) << 24) |
+	    (((Elem64)a[start+4]) << 32) |
+	    (((Elem64)a[start+5]) << 40) |
+	    (((Elem64)a[start+6]) << 48) |
+	    (((Elem64)a[start+7]) << 56);
+
+      start += 8;
+      axpy64(out + bCols*i, val, b + bCols*j, bCols);
+    }
+  }
+}
+
+// Each packed word of 'a' holds 'ratio' values of 'basis' bits. The query
+// 'b' is first split into 'ratio' contiguous vectors (bs[k][j] = b[j*ratio+k]),
+// so that eight packed words can be multiplied at once.
+AVX2_INLINE int packed32(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aCols, const unsigned basis, const unsigned ratio)
+{
+  const Elem32 mask = (((Elem32)1) << basis) - 1;
+  Elem32 *bs = malloc(ratio*aCols*sizeof(Elem32));
+  if (!bs)
+    return 0;
+
+  for (size_t j = 0; j < aCols; j++) {
+    for (unsigned k = 0; k < ratio; k++) {
+      bs[k*aCols + j] = b[j*ratio + k];
+    }
+  }
+
+  __m256i vmask = _mm256_set1_epi32((int)mask);
+  for (size_t i = 0; i < aRows; i++) {
+    const Elem32 *row = a + aCols*i;
+    __m256i acc = _mm256_setzero_si256();
+    size_t j = 0;
+
+    for (; j + 8 <= aCols; j += 8) {
+      __m256i db = _mm256_loadu_si256((const __m256i *)(row + j));
+      for (unsigned k = 0; k < ratio; k++) {
+        __m256i val = _mm256_and_si256(_mm256_srli_epi32(db, basis*k), vmask);
+        __m256i q = _mm256_loadu_si256((const __m256i *)(bs + k*aCols + j));
+        acc = _mm256_add_epi32(acc, _mm256_mullo_epi32(val, q));
+      }
+    }
+
+    Elem32 lanes[8];
+    _mm256_storeu_si256((__m256i *)lanes, acc);
+    Elem32 tmp = 0;
+    for (int l = 0; l < 8; l++) {
+      tmp += lanes[l];
+    }
+
+    for (; j < aCols; j++) {
+      for (unsigned k = 0; k < ratio; k++) {
+        tmp += ((row[j] >> (basis*k)) & mask) * b[j*ratio + k];
+      }
+    }
+    out[i] += tmp;
+  }
+
+  free(bs);
+  return 1;
+}
+
+// Packed values are below 2^32, so a single 32x64-bit multiply suffices.
+AVX2_INLINE int packed64(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, const unsigned basis, const unsigned ratio)
+{
+  const Elem64 mask = (((Elem64)1) << basis) - 1;
+  Elem64 *bs = malloc(ratio*aCols*sizeof(Elem64));
+  if (!bs)
+    return 0;
+
+  for (size_t j = 0; j < aCols; j++) {
+    for (unsigned k = 0; k < ratio; k++) {
+      bs[k*aCols + j] = b[j*ratio + k];
+    }
+  }
+
+  __m256i vmask = _mm256_set1_epi64x((long long)mask);
+  for (size_t i = 0; i < aRows; i++) {
+    const Elem64 *row = a + aCols*i;
+    __m256i acc = _mm256_setzero_si256();
+    size_t j = 0;
+
+    for (; j + 4 <= aCols; j += 4) {
+      __m256i db = _mm256_loadu_si256((const __m256i *)(row + j));
+      for (unsigned k = 0; k < ratio; k++) {
+        __m256i val = _mm256_and_si256(_mm256_srli_epi64(db, basis*k), vmask);
+        __m256i q = _mm256_loadu_si256((const __m256i *)(bs + k*aCols + j));
+        __m256i prod = _mm256_add_epi64(_mm256_mul_epu32(val, q),
+            _mm256_slli_epi64(_mm256_mul_epu32(val, _mm256_srli_epi64(q, 32)), 32));
+        acc = _mm256_add_epi64(acc, prod);
+      }
+    }
+
+    Elem64 lanes[4];
+    _mm256_storeu_si256((__m256i *)lanes, acc);
+    Elem64 tmp = lanes[0] + lanes[1] + lanes[2] + lanes[3];
+
+    for (; j < aCols; j++) {
+      for (unsigned k = 0; k < ratio; k++) {
+        tmp += ((row[j] >> (basis*k)) & mask) * b[j*ratio + k];
+      }
+    }
+    out[i] += tmp;
+  }
+
+  free(bs);
+  return 1;
+}
+
+#define KERNEL packed32
+AVX2 static int packed32_layout(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  SPECIALIZE_PACKED(SQUISH_LAYOUTS_32)
+}
+#undef KERNEL
+
+AVX2 void matMulVecPacked32_avx2(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  if (!packed32_layout(out, a, b, aRows, aCols, basis, ratio))
+    matMulVecPacked32_scalar(out, a, b, aRows, aCols, basis, ratio);
+}
+
+#define KERNEL packed64
+AVX2 static int packed64_layout(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  SPECIALIZE_PACKED(SQUISH_LAYOUTS_64)
+}
+#undef KERNEL
+
+AVX2 void matMulVecPacked64_avx2(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  if (!packed64_layout(out, a, b, aRows, aCols, basis, ratio))
+    matMulVecPacked64_scalar(out, a, b, aRows, aCols, basis, ratio);
+}
+
+#endif
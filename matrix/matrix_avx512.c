@@ -0,0 +1,223 @@
+//go:build cgo
+
+#include "matrix.h"
+#include "kernels.h"
+
+#if defined(__x86_64__)
+#include <immintrin.h>
+#include <stdlib.h>
+
+#define AVX512 __attribute__((target("avx512f,avx512dq")))
+#define AVX512_INLINE static inline __attribute__((target("avx512f,avx512dq"), always_inline))
+
+// out[j] += s * b[j]
+AVX512_INLINE void axpy32(Elem32 *out, Elem32 s, const Elem32 *b, size_t n)
+{
+  __m512i vs = _mm512_set1_epi32((int)s);
+  size_t j = 0;
+  for (; j + 16 <= n; j += 16) {
+    __m512i o = _mm512_loadu_si512((const void *)(out + j));
+    __m512i v = _mm512_loadu_si512((const void *)(b + j));
+    o = _mm512_add_epi32(o, _mm512_mullo_epi32(vs, v));
+    _mm512_storeu_si512((void *)(out + j), o);
+  }
+  for (; j < n; j++) {
+    out[j] += s*b[j];
+  }
+}
+
+AVX512_INLINE void axpy64(Elem64 *out, Elem64 s, const Elem64 *b, size_t n)
+{
+  __m512i vs = _mm512_set1_epi64((long long)s);
+  size_t j = 0;
+  for (; j + 8 <= n; j += 8) {
+    __m512i o = _mm512_loadu_si512((const void *)(out + j));
+    __m512i v = _mm512_loadu_si512((const void *)(b + j));
+    o = _mm512_add_epi64(o, _mm512_mullo_epi64(vs, v));
+    _mm512_storeu_si512((void *)(out + j), o);
+  }
+  for (; j < n; j++) {
+    out[j] += s*b[j];
+  }
+}
+
+AVX512 void matMulBlock32_avx512(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols)
+{
+  for (size_t i = 0; i < aRows; i++) {
+    for (size_t k = 0; k < bRows; k++) {
+      axpy32(out + bCols*i, a[aStride*i + k], b + bCols*k, bCols);
+    }
+  }
+}
+
+AVX512 void matMulBlock64_avx512(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aStride, size_t bRows, size_t bCols)
+{
+  for (size_t i = 0; i < aRows; i++) {
+    for (size_t k = 0; k < bRows; k++) {
+      axpy64(out + bCols*i, a[aStride*i + k], b + bCols*k, bCols);
+    }
+  }
+}
+
+AVX512 void randMatMul32_avx512(Elem32* out, const uint8_t *a, const Elem32 *b,
+    size_t aRows, size_t aCols, size_t bCols)
+{
+  Elem32 val;
+  Elem64 start = 0;
+
+  for (size_t i = 0; i < aRows; i++) {
+    for (size_t j = 0; j < aCols; j++) {
+      val = ((Elem32)a[start+0]) |
+	    (((Elem32)a[start+1]) << 8) |
+	    (((Elem32)a[start+2]) << 16) |
+	    (((Elem32)a[start+3]) << 24);
+
+      start += 4;
+      axpy32(out + bCols*i, val, b + bCols*j, bCols);
+    }
+  }
+}
+
+AVX512 void randMatMul64_avx512(Elem64* out, const uint8_t *a, const Elem64 *b,
+    size_t aRows, size_t aCols, size_t bCols)
+{
+  Elem64 val;
+  Elem64 start = 0;
+
+  for (size_t i = 0; i < aRows; i++) {
+    for (size_t j = 0; j < aCols; j++) {
+      val = ((Elem64)a[start+0]) |
+	    (((Elem64)a[start+1]) << 8) |
+	    (((Elem64)a[start+2]) << 16) |
+	    (((Elem64)a[start+3]) << 24) |
+	    (((Elem64)a[start+4]) << 32) |
+	    (((Elem64)a[start+5]) << 40) |
+	    (((Elem64)a[start+6]) << 48) |
+	    (((Elem64)a[start+7]) << 56);
+
+      start += 8;
+      axpy64(out + bCols*i, val, b + bCols*j, bCols);
+    }
+  }
+}
+
+// Each packed word of 'a' holds 'ratio' values of 'basis' bits. The query
+// 'b' is first split into 'ratio' contiguous vectors (bs[k][j] = b[j*ratio+k]),
+// so that sixteen (resp. eight) packed words can be multiplied at once.
+AVX512_INLINE int packed32(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aCols, const unsigned basis, const unsigned ratio)
+{
+  const Elem32 mask = (((Elem32)1) << basis) - 1;
+  Elem32 *bs = malloc(ratio*aCols*sizeof(Elem32));
+  if (!bs)
+    return 0;
+
+  for (size_t j = 0; j < aCols; j++) {
+    for (unsigned k = 0; k < ratio; k++) {
+      bs[k*aCols + j] = b[j*ratio + k];
+    }
+  }
+
+  __m512i vmask = _mm512_set1_epi32((int)mask);
+  for (size_t i = 0; i < aRows; i++) {
+    const Elem32 *row = a + aCols*i;
+    __m512i acc = _mm512_setzero_si512();
+    size_t j = 0;
+
+    for (; j + 16 <= aCols; j += 16) {
+      __m512i db = _mm512_loadu_si512((const void *)(row + j));
+      for (unsigned k = 0; k < ratio; k++) {
+        __m512i val = _mm512_and_si512(_mm512_srli_epi32(db, basis*k), vmask);
+        __m512i q = _mm512_loadu_si512((const void *)(bs + k*aCols + j));
+        acc = _mm512_add_epi32(acc, _mm512_mullo_epi32(val, q));
+      }
+    }
+
+    Elem32 tmp = (Elem32)_mm512_reduce_add_epi32(acc);
+    for (; j < aCols; j++) {
+      for (unsigned k = 0; k < ratio; k++) {
+        tmp += ((row[j] >> (basis*k)) & mask) * b[j*ratio + k];
+      }
+    }
+    out[i] += tmp;
+  }
+
+  free(bs);
+  return 1;
+}
+
+AVX512_INLINE int packed64(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, const unsigned basis, const unsigned ratio)
+{
+  const Elem64 mask = (((Elem64)1) << basis) - 1;
+  Elem64 *bs = malloc(ratio*aCols*sizeof(Elem64));
+  if (!bs)
+    return 0;
+
+  for (size_t j = 0; j < aCols; j++) {
+    for (unsigned k = 0; k < ratio; k++) {
+      bs[k*aCols + j] = b[j*ratio + k];
+    }
+  }
+
+  __m512i vmask = _mm512_set1_epi64((long long)mask);
+  for (size_t i = 0; i < aRows; i++) {
+    const Elem64 *row = a + aCols*i;
+    __m512i acc = _mm512_setzero_si512();
+    size_t j = 0;
+
+    for (; j + 8 <= aCols; j += 8) {
+      __m512i db = _mm512_loadu_si512((const void *)(row + j));
+      for (unsigned k = 0; k < ratio; k++) {
+        __m512i val = _mm512_and_si512(_mm512_srli_epi64(db, basis*k), vmask);
+        __m512i q = _mm512_loadu_si512((const void *)(bs + k*aCols + j));
+        acc = _mm512_add_epi64(acc, _mm512_mullo_epi64(val, q));
+      }
+    }
+
+    Elem64 tmp = (Elem64)_mm512_reduce_add_epi64(acc);
+    for (; j < aCols; j++) {
+      for (unsigned k = 0; k < ratio; k++) {
+        tmp += ((row[j] >> (basis*k)) & mask) * b[j*ratio + k];
+      }
+    }
+    out[i] += tmp;
+  }
+
+  free(bs);
+  return 1;
+}
+
+#define KERNEL packed32
+AVX512 static int packed32_layout(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  SPECIALIZE_PACKED(SQUISH_LAYOUTS_32)
+}
+#undef KERNEL
+
+AVX512 void matMulVecPacked32_avx512(Elem32 *out, const Elem32 *a, const Elem32 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  if (!packed32_layout(out, a, b, aRows, aCols, basis, ratio))
+    matMulVecPacked32_scalar(out, a, b, aRows, aCols, basis, ratio);
+}
+
+#define KERNEL packed64
+AVX512 static int packed64_layout(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  SPECIALIZE_PACKED(SQUISH_LAYOUTS_64)
+}
+#undef KERNEL
+
+AVX512 void matMulVecPacked64_avx512(Elem64 *out, const Elem64 *a, const Elem64 *b,
+    size_t aRows, size_t aCols, unsigned basis, unsigned ratio)
+{
+  if (!packed64_layout(out, a, b, aRows, aCols, basis, ratio))
+    matMulVecPacked64_scalar(out, a, b, aRows, aCols, basis, ratio);
+}
+
+#endif
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ryanleh/simplepir/rand"
@@ -76,6 +78,80 @@ func TestToFile64(t *testing.T) {
 	testToFile[Elem64](t, "test64.log")
 }
 
+// Decoding untrusted input either fails or yields a well-formed matrix.
+func fuzzGobDecode[U Elem](f *testing.F) {
+	prg := rand.NewRandomBufPRG()
+	for _, dims := range [][2]uint64{{1, 1}, {3, 5}} {
+		buf, _ := Rand[U](prg, dims[0], dims[1], 0).GobEncode()
+		f.Add(buf)
+	}
+	forged, _ := Matrix[U]{rows: 1 << 40, cols: 1 << 40}.GobEncode()
+	f.Add(forged)
+
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		var m Matrix[U]
+		if m.GobDecode(buf) != nil {
+			return
+		}
+		if m.rows*m.cols != uint64(len(m.data)) {
+			t.Fatalf("Decoded %d-by-%d matrix with %d elements", m.rows, m.cols, len(m.data))
+		}
+
+		out, _ := m.GobEncode()
+		var n Matrix[U]
+		if err := n.GobDecode(out); err != nil || !m.Equals(&n) {
+			t.Fatalf("Round trip failed: %v", err)
+		}
+	})
+}
+
+func FuzzGobDecode32(f *testing.F) {
+	fuzzGobDecode[Elem32](f)
+}
+
+func FuzzGobDecode64(f *testing.F) {
+	fuzzGobDecode[Elem64](f)
+}
+
+func fuzzReadFromFile[U Elem](f *testing.F) {
+	f.Add([]byte("2,2\n1,2,3,4,"))
+	f.Add([]byte("0,0\n"))
+	f.Add([]byte("1099511627776,1099511627776\n1,"))
+	f.Add([]byte("18446744073709551615,2\n1,2,"))
+	f.Add([]byte("1,1\n-1,"))
+
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		fn := filepath.Join(t.TempDir(), "m")
+		if err := os.WriteFile(fn, buf, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		var m Matrix[U]
+		if m.ReadFromFile(fn) != nil {
+			return
+		}
+		if m.rows*m.cols != uint64(len(m.data)) {
+			t.Fatalf("Read %d-by-%d matrix with %d elements", m.rows, m.cols, len(m.data))
+		}
+
+		if err := m.WriteToFile(fn); err != nil {
+			t.Fatal(err)
+		}
+		var n Matrix[U]
+		if err := n.ReadFromFile(fn); err != nil || !m.Equals(&n) {
+			t.Fatalf("Round trip failed: %v", err)
+		}
+	})
+}
+
+func FuzzReadFromFile32(f *testing.F) {
+	fuzzReadFromFile[Elem32](f)
+}
+
+func FuzzReadFromFile64(f *testing.F) {
+	fuzzReadFromFile[Elem64](f)
+}
+
 func testAdd[U Elem](t *testing.T, r1 uint64, c1 uint64) {
 	rand := rand.NewRandomBufPRG()
 
@@ -171,6 +247,32 @@ func testMul[U Elem](t *testing.T, r1 uint64, c1 uint64, r2 uint64, c2 uint64) {
 		fmt.Println("Failed seeded with multiple keys")
 		t.Fail()
 	}
+
+	// Test right-seeded multiplication, expanding a few rows at a time
+	prevBlockBytes := seededBlockBytes
+	seededBlockBytes = 3 * c2 * (U(0).Bitlen() / 8)
+	defer func() { seededBlockBytes = prevBlockBytes }()
+
+	key3 := rand.RandomPRGKey()
+	rand9 := rand.NewBufPRG(rand.NewPRG(key3))
+	rand10 := rand.NewBufPRG(rand.NewPRG(key3))
+	rand11 := rand.NewBufPRG(rand.NewPRG(key))
+	m9 := Rand[U](rand9, r2, c2, 0)
+	m9.Concat(Rand[U](rand.NewBufPRG(rand.NewPRG(key)), r2, c2, 0))
+	m10 := &MatrixSeeded[U]{
+		src:  []IoRandSource{rand10, rand11},
+		rows: []uint64{r2, r2},
+		cols: c2,
+	}
+
+	m11 := Rand[U](rand1, r1, 2*r2, 0)
+	z9 := Mul(m11, m9)
+	z10 := MulSeededRight(m11, m10)
+
+	if !z9.Equals(z10) {
+		fmt.Println("Failed right-seeded")
+		t.Fail()
+	}
 }
 
 func TestMul32(t *testing.T) {
@@ -236,3 +338,96 @@ func TestMulVecPackedBig32(t *testing.T) {
 func TestMulPackedBig64(t *testing.T) {
 	testMulPacked[Elem64](t, 810, 132)
 }
+
+// Check every squishing layout, on every kernel, against Mul.
+func testMulPackedLayouts[U Elem](t *testing.T, r1 uint64, c1 uint64) {
+	defer UseKernel(CurrentKernel())
+	rand := rand.NewRandomBufPRG()
+
+	for _, params := range SquishLayouts[U]() {
+		m2 := Rand[U](rand, c1, 1, 0)
+		m1 := Rand[U](rand, r1, c1, 1<<params.Basis)
+
+		res1 := Mul(m1, m2)
+		m1.SquishWith(params)
+		m2.AppendZeros(m1.Cols()*params.Ratio - m2.Rows())
+
+		for k := KernelScalar; k <= BestKernel(); k++ {
+			UseKernel(k)
+			if !res1.Equals(MulVecPackedWith(m1, m2, params)) {
+				t.Fatalf("Layout %v: kernel %v does not match", params, k)
+			}
+		}
+
+		res2 := Zeros[U](r1, 1)
+		goMatMulVecPacked(res2.data, m1.data, m2.data, r1, m1.Cols(), params.Basis, params.Ratio)
+		if !res1.Equals(res2) {
+			t.Fatalf("Layout %v: Go kernel does not match", params)
+		}
+
+		// Batched: one column per vector
+		batch := Rand[U](rand, m2.Rows(), 5, 0)
+		want := New[U](r1, 5)
+		for c := uint64(0); c < 5; c++ {
+			vec := New[U](batch.Rows(), 1)
+			for r := uint64(0); r < batch.Rows(); r++ {
+				vec.Set(r, 0, batch.Get(r, c))
+			}
+			col := MulVecPackedWith(m1, vec, params)
+			for r := uint64(0); r < r1; r++ {
+				want.Set(r, c, col.Get(r, 0))
+			}
+		}
+		if !want.Equals(MulPackedWith(m1, batch, params)) {
+			t.Fatalf("Layout %v: batched kernel does not match", params)
+		}
+	}
+}
+
+func TestMulPackedLayouts32(t *testing.T) {
+	testMulPackedLayouts[Elem32](t, 37, 1391)
+}
+
+func TestMulPackedLayouts64(t *testing.T) {
+	testMulPackedLayouts[Elem64](t, 37, 1391)
+}
+
+// Enough rows for MulPackedWith to split 'a' into several tiles.
+func testMulPackedTiles[U Elem](t *testing.T) {
+	rand := rand.NewRandomBufPRG()
+	params := SquishLayouts[U]()[0]
+	a := Rand[U](rand, 2053, 4096, 1<<params.Basis)
+	b := Rand[U](rand, 4096, 3, 0)
+	want := Mul(a, b)
+
+	a.SquishWith(params)
+	if !want.Equals(MulPackedWith(a, b, params)) {
+		t.Fatal("Tiled product does not match")
+	}
+}
+
+func TestMulPackedTiles32(t *testing.T) {
+	testMulPackedTiles[Elem32](t)
+}
+
+func TestMulPackedTiles64(t *testing.T) {
+	testMulPackedTiles[Elem64](t)
+}
+
+func TestChooseSquishParams(t *testing.T) {
+	if p, ok := ChooseSquishParams[Elem32](256); !ok || p.Ratio != 4 {
+		t.Fatalf("Got %v for p = 256", p)
+	}
+	if p, ok := ChooseSquishParams[Elem32](991); !ok || p != (SquishParams{10, 3}) {
+		t.Fatalf("Got %v for p = 991", p)
+	}
+	if _, ok := ChooseSquishParams[Elem32](1 << 17); ok {
+		t.Fatal("Squished p = 2^17 into 32 bits")
+	}
+	if p, ok := ChooseSquishParams[Elem64](95640378); !ok || p != (SquishParams{30, 2}) {
+		t.Fatalf("Got %v for p = 95640378", p)
+	}
+	if p, ok := ChooseSquishParams[Elem64](1 << 32); !ok || p != (SquishParams{32, 2}) {
+		t.Fatalf("Got %v for p = 2^32", p)
+	}
+}
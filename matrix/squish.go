@@ -1,24 +1,71 @@
 package matrix
 
-// #cgo CFLAGS: -O3 -march=native
-// #include "matrix.h"
-import "C"
-
 import "log"
 
-const squishBasis32 = C.BASIS_32
-const squishRatio32 = C.COMPRESSION_32
+// Packing of 'Ratio' values of 'Basis' bits each into a single element.
+type SquishParams struct {
+	Basis uint64
+	Ratio uint64
+}
+
+// Layouts with a specialized MulVecPacked kernel, by decreasing ratio.
+// Must match SQUISH_LAYOUTS_* in matrix.h
+var squishLayouts32 = []SquishParams{{8, 4}, {10, 3}, {16, 2}}
+var squishLayouts64 = []SquishParams{{8, 8}, {16, 4}, {21, 3}, {30, 2}, {32, 2}}
+
+// Used by Squish and MulVecPacked
+const squishBasis32 = 10
+const squishRatio32 = 3
 
-const squishBasis64 = C.BASIS_64
-const squishRatio64 = C.COMPRESSION_64
+const squishBasis64 = 30
+const squishRatio64 = 2
+
+func SquishLayouts[T Elem]() []SquishParams {
+	switch T(0).Bitlen() {
+	case 32:
+		return squishLayouts32
+	case 64:
+		return squishLayouts64
+	default:
+		panic("Shouldn't get here")
+	}
+}
+
+// Returns the layout packing the most values of Z_pMod into each element,
+// or false if no layout fits.
+func ChooseSquishParams[T Elem](pMod uint64) (SquishParams, bool) {
+	for _, params := range SquishLayouts[T]() {
+		if params.Fits(pMod) {
+			return params, true
+		}
+	}
+	return SquishParams{}, false
+}
+
+func (p SquishParams) Fits(pMod uint64) bool {
+	return !(pMod > (1 << p.Basis))
+}
+
+func (p SquishParams) check(bitlen uint64) {
+	if p.Ratio < 2 || p.Basis == 0 || p.Basis*p.Ratio > bitlen {
+		panic("Bad squishing params")
+	}
+}
+
+// Compresses the matrix to store it in 'packed' form, with the default
+// parameters (see SquishBasis and SquishRatio).
+func (m *Matrix[T]) Squish() {
+	m.SquishWith(SquishParams{m.SquishBasis(), m.SquishRatio()})
+}
 
 // Compresses the matrix to store it in 'packed' form.
 // Specifically, this method squishes the matrix by representing each
 // group of 'delta' consecutive values as a single database Element,
 // where each value uses 'basis' bits.
-func (m *Matrix[T]) Squish() {
-	basis := m.SquishBasis()
-	delta := m.SquishRatio()
+func (m *Matrix[T]) SquishWith(params SquishParams) {
+	params.check(T(0).Bitlen())
+	basis := params.Basis
+	delta := params.Ratio
 
 	n := Zeros[T](m.rows, (m.cols+delta-1)/delta)
 
@@ -27,7 +74,7 @@ func (m *Matrix[T]) Squish() {
 			for k := uint64(0); k < delta; k++ {
 				if delta*j+k < m.cols {
 					val := m.Get(i, delta*j+k)
-					if val >= (1 << m.SquishBasis()) {
+					if uint64(val) >= (1 << basis) {
 						log.Fatalf("Database entry %v too large to squish", val)
 					}
 					n.data[i*n.cols+j] += (val << (k * basis))
@@ -0,0 +1,303 @@
+package pir
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+// Batch PIR with bucketing: every record is stored in exactly one of
+// NumBuckets buckets, the less full of two chosen by hashing, so that the
+// buckets together hold the database once. The client sends Slots queries
+// to every bucket, for the records of the batch it holds and dummies for
+// the rest, and the server answers each bucket's queries in a single scan
+// (see AnswerMulti): about one scan of the database per batch of up to K
+// records, rather than one per record.
+type BatchLayout struct {
+	Num        uint64      // number of db entries
+	K          uint64      // maximum batch size
+	NumBuckets uint64      // K
+	Slots      uint64      // queries per bucket
+	Key        rand.PRGKey // public hash key
+
+	buckets [][]uint64 // sorted indices held by each bucket
+	bucket  []uint32   // bucket holding each index
+}
+
+// Largest probability that a random batch of K records overflows the
+// Slots of some bucket.
+const batchOverflow = 1.0 / (1 << 20)
+
+func NewBatchLayout(num, k uint64, key *rand.PRGKey) *BatchLayout {
+	if num == 0 || k == 0 {
+		panic("Empty batch")
+	}
+	if k > math.MaxUint32 {
+		panic("Batch too large")
+	}
+
+	l := &BatchLayout{
+		Num:        num,
+		K:          k,
+		NumBuckets: k,
+		Key:        *key,
+	}
+	l.Slots = batchSlots(k, l.NumBuckets)
+
+	// Two choices keep the buckets, and so the padding, even
+	l.buckets = make([][]uint64, l.NumBuckets)
+	l.bucket = make([]uint32, num)
+	for i := uint64(0); i < num; i++ {
+		b0, b1 := l.candidates(i)
+		b := b0
+		if len(l.buckets[b1]) < len(l.buckets[b0]) {
+			b = b1
+		}
+		l.buckets[b] = append(l.buckets[b], i)
+		l.bucket[i] = uint32(b)
+	}
+
+	return l
+}
+
+// The two buckets that may hold record 'index'.
+func (l *BatchLayout) candidates(index uint64) (uint64, uint64) {
+	var in [len(rand.PRGKey{}) + 8]byte
+	copy(in[:], l.Key[:])
+	binary.LittleEndian.PutUint64(in[len(l.Key):], index)
+	sum := sha256.Sum256(in[:])
+
+	return binary.LittleEndian.Uint64(sum[:8]) % l.NumBuckets,
+		binary.LittleEndian.Uint64(sum[8:16]) % l.NumBuckets
+}
+
+// Smallest number of slots such that k random records overflow no bucket,
+// but with probability batchOverflow (by the union bound over buckets).
+func batchSlots(k, buckets uint64) uint64 {
+	if buckets == 1 {
+		return k
+	}
+
+	// The load of a bucket is binomial: pmf[j] = P(j of the k records)
+	p := 1 / float64(buckets)
+	pmf := make([]float64, k+1)
+	pmf[0] = math.Pow(1-p, float64(k))
+	for j := uint64(1); j <= k; j++ {
+		pmf[j] = pmf[j-1] * float64(k-j+1) / float64(j) * p / (1 - p)
+	}
+
+	tail := 0.0
+	for t := k; t > 0; t-- {
+		tail += pmf[t]
+		if tail*float64(buckets) > batchOverflow {
+			return t
+		}
+	}
+	return 1
+}
+
+// Number of entries per bucket database; smaller buckets are padded.
+func (l *BatchLayout) BucketSize() uint64 {
+	size := uint64(1)
+	for _, b := range l.buckets {
+		if uint64(len(b)) > size {
+			size = uint64(len(b))
+		}
+	}
+	return size
+}
+
+// Position of record 'index' in the database of 'bucket'.
+func (l *BatchLayout) position(bucket, index uint64) uint64 {
+	b := l.buckets[bucket]
+	pos := sort.Search(len(b), func(i int) bool { return b[i] >= index })
+	if pos == len(b) || b[pos] != index {
+		panic("Record not in bucket")
+	}
+	return uint64(pos)
+}
+
+type BatchServer[T matrix.Elem] struct {
+	layout  *BatchLayout
+	servers []*Server[T]
+}
+
+func NewBatchServer[T matrix.Elem](db *Database[T], k uint64) *BatchServer[T] {
+	layout := NewBatchLayout(db.Info.Num, k, rand.RandomPRGKey())
+	return NewBatchServerSeed(db, layout, rand.RandomPRGKey())
+}
+
+// All bucket servers share the same DBInfo and A matrix seed.
+func NewBatchServerSeed[T matrix.Elem](db *Database[T], layout *BatchLayout, seed *rand.PRGKey) *BatchServer[T] {
+	if layout.Num != db.Info.Num {
+		panic("Layout does not match database")
+	}
+
+	size := layout.BucketSize()
+	info := NewDBInfo(T(0).Bitlen(), size, db.Info.RowLength)
+
+	s := &BatchServer[T]{layout: layout}
+	for _, bucket := range layout.buckets {
+		vals := make([]T, size)
+		for pos, i := range bucket {
+			vals[pos] = T(db.GetElem(i))
+		}
+
+		bucketDB := NewDatabaseFixedParams[T](size, db.Info.RowLength, vals, info.Params)
+		s.servers = append(s.servers, NewServerSeed(bucketDB, seed))
+	}
+
+	return s
+}
+
+func (s *BatchServer[T]) Layout() *BatchLayout {
+	return s.layout
+}
+
+func (s *BatchServer[T]) Hints() []*matrix.Matrix[T] {
+	hints := make([]*matrix.Matrix[T], len(s.servers))
+	for i, server := range s.servers {
+		hints[i] = server.Hint()
+	}
+	return hints
+}
+
+func (s *BatchServer[T]) MatrixA() *rand.PRGKey {
+	return s.servers[0].MatrixA()
+}
+
+// Shared by every bucket.
+func (s *BatchServer[T]) DBInfo() *DBInfo {
+	return s.servers[0].DBInfo()
+}
+
+// Answers Slots queries per bucket, in order, with one scan of each
+// bucket; buckets are answered in parallel.
+func (s *BatchServer[T]) Answer(queries []*Query[T]) []*Answer[T] {
+	slots := int(s.layout.Slots)
+	if len(queries) != len(s.servers)*slots {
+		panic("Expected Slots queries per bucket")
+	}
+
+	answers := make([]*Answer[T], len(queries))
+	var wg sync.WaitGroup
+	for b := range s.servers {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			copy(answers[b*slots:], s.servers[b].AnswerMulti(queries[b*slots:(b+1)*slots]))
+		}(b)
+	}
+	wg.Wait()
+
+	return answers
+}
+
+type BatchClient[T matrix.Elem] struct {
+	prg     matrix.IoRandSource
+	layout  *BatchLayout
+	clients []*Client[T]
+}
+
+type BatchSecret[T matrix.Elem] struct {
+	secrets []*Secret[T]
+	indices map[uint64]uint64 // query -> record, for non-dummy queries
+}
+
+func NewBatchClient[T matrix.Elem](hints []*matrix.Matrix[T], matrixAseed *rand.PRGKey, dbinfo *DBInfo, layout *BatchLayout) *BatchClient[T] {
+	return NewBatchClientWithSource(hints, matrixAseed, dbinfo, layout, rand.NewRandomBufPRG())
+}
+
+// Draws the secrets, errors and dummy positions of every bucket from 'src',
+// e.g. to replay queries in tests; see NewClientWithSource, whose warning
+// applies.
+func NewBatchClientWithSource[T matrix.Elem](hints []*matrix.Matrix[T], matrixAseed *rand.PRGKey, dbinfo *DBInfo,
+	layout *BatchLayout, src matrix.IoRandSource) *BatchClient[T] {
+	if uint64(len(hints)) != layout.NumBuckets {
+		panic("Expected one hint per bucket")
+	}
+
+	c := &BatchClient[T]{
+		prg:    src,
+		layout: layout,
+	}
+	for _, hint := range hints {
+		c.clients = append(c.clients, NewClientWithSource(hint, matrixAseed, dbinfo, src))
+	}
+
+	return c
+}
+
+// Returned by BatchClient.Query when more records of the batch than Slots
+// fall in one bucket, which for a random batch happens with probability at
+// most batchOverflow. The placement is fixed, so split the batch instead
+// of retrying it.
+var ErrBucketFull = errors.New("pir: too many records of the batch in one bucket")
+
+// Builds Slots queries per bucket for up to K distinct records.
+func (c *BatchClient[T]) Query(indices []uint64) (*BatchSecret[T], []*Query[T], error) {
+	seen := make(map[uint64]bool)
+	var items []uint64
+	for _, i := range indices {
+		if i >= c.layout.Num {
+			return nil, nil, fmt.Errorf("pir: index %d out of range", i)
+		}
+		if !seen[i] {
+			seen[i] = true
+			items = append(items, i)
+		}
+	}
+	if uint64(len(items)) > c.layout.K {
+		return nil, nil, fmt.Errorf("pir: batch of %d records, at most %d allowed", len(items), c.layout.K)
+	}
+
+	// Fill the slots of each bucket in turn
+	slots := c.layout.Slots
+	s := &BatchSecret[T]{indices: make(map[uint64]uint64)}
+	used := make([]uint64, c.layout.NumBuckets)
+	for _, i := range items {
+		b := uint64(c.layout.bucket[i])
+		if used[b] == slots {
+			return nil, nil, ErrBucketFull
+		}
+		s.indices[b*slots+used[b]] = i
+		used[b]++
+	}
+
+	size := c.layout.BucketSize()
+	queries := make([]*Query[T], c.layout.NumBuckets*slots)
+	for q := range queries {
+		b := uint64(q) / slots
+		pos := c.prg.Uint64() % size
+		if i, ok := s.indices[uint64(q)]; ok {
+			pos = c.layout.position(b, i)
+		}
+
+		secret := c.clients[b].PreprocessQuery()
+		queries[q] = c.clients[b].QueryPreprocessed(pos, secret)
+		s.secrets = append(s.secrets, secret)
+	}
+
+	return s, queries, nil
+}
+
+// Returns the value of every requested record, keyed by index.
+func (c *BatchClient[T]) Recover(s *BatchSecret[T], answers []*Answer[T]) map[uint64]uint64 {
+	if len(answers) != len(s.secrets) {
+		panic("Expected Slots answers per bucket")
+	}
+
+	out := make(map[uint64]uint64)
+	for q, i := range s.indices {
+		out[i] = c.clients[q/c.layout.Slots].Recover(s.secrets[q], answers[q])
+	}
+
+	return out
+}
@@ -0,0 +1,107 @@
+package pir
+
+import (
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func testBatch[T matrix.Elem](t *testing.T, N uint64, d uint64, k uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+
+	layout := NewBatchLayout(N, k, testKey(prg))
+	server := NewBatchServerSeed(db, layout, testKey(prg))
+	client := NewBatchClientWithSource(server.Hints(), server.MatrixA(), server.DBInfo(), layout, prg)
+
+	// Every record is held by exactly one bucket
+	total := uint64(0)
+	for b := range layout.buckets {
+		total += uint64(len(layout.buckets[b]))
+	}
+	if total != N {
+		t.Fatalf("Buckets hold %d records, expected %d", total, N)
+	}
+
+	for _, num := range []uint64{k, k / 2} {
+		indices := make([]uint64, num)
+		for i := range indices {
+			indices[i] = prg.Uint64() % N
+		}
+
+		secret, queries, err := client.Query(indices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uint64(len(queries)) != layout.NumBuckets*layout.Slots {
+			t.Fatalf("Sent %d queries for %d buckets of %d slots", len(queries), layout.NumBuckets, layout.Slots)
+		}
+
+		vals := client.Recover(secret, server.Answer(queries))
+		for _, i := range indices {
+			if v, ok := vals[i]; !ok || v != db.GetElem(i) {
+				t.Fatalf("Querying index %d: Got %d instead of %d", i, v, db.GetElem(i))
+			}
+		}
+	}
+
+	if _, _, err := client.Query([]uint64{N}); err == nil {
+		t.Fatal("Queried out of range")
+	}
+	tooMany := make([]uint64, k+1)
+	for i := range tooMany {
+		tooMany[i] = uint64(i)
+	}
+	if _, _, err := client.Query(tooMany); err == nil {
+		t.Fatal("Queried a batch larger than K")
+	}
+
+	// More records of one bucket than it has slots
+	if b := layout.buckets[0]; uint64(len(b)) > layout.Slots && layout.Slots < k {
+		if _, _, err := client.Query(b[:layout.Slots+1]); err != ErrBucketFull {
+			t.Fatalf("Overfilled a bucket: got %v", err)
+		}
+	}
+}
+
+// Clients drawing from the same source send the same queries.
+func testBatchReplay[T matrix.Elem](t *testing.T, N uint64, d uint64, k uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+	server := NewBatchServerSeed(db, NewBatchLayout(N, k, testKey(prg)), testKey(prg))
+
+	key := testKey(prg)
+	var sent [2][]*Query[T]
+	for i := range sent {
+		src := rand.NewBufPRG(rand.NewPRG(key))
+		client := NewBatchClientWithSource(server.Hints(), server.MatrixA(), server.DBInfo(), server.Layout(), src)
+		_, queries, err := client.Query([]uint64{0, N - 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent[i] = queries
+	}
+
+	for i := range sent[0] {
+		if !sent[0][i].Query.Equals(sent[1][i].Query) {
+			t.Fatalf("Query %d differs between replays", i)
+		}
+	}
+}
+
+func TestBatch32(t *testing.T) {
+	testBatch[matrix.Elem32](t, uint64(1<<14), uint64(8), 32)
+}
+
+func TestBatch64(t *testing.T) {
+	testBatch[matrix.Elem64](t, uint64(1<<10), uint64(32), 8)
+}
+
+func TestBatchSmall32(t *testing.T) {
+	testBatch[matrix.Elem32](t, uint64(100), uint64(3), 2)
+}
+
+func TestBatchReplay32(t *testing.T) {
+	testBatchReplay[matrix.Elem32](t, uint64(1<<10), uint64(8), 4)
+}
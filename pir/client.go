@@ -1,13 +1,16 @@
 package pir
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ryanleh/simplepir/lwe"
 	"github.com/ryanleh/simplepir/matrix"
 	"github.com/ryanleh/simplepir/rand"
 )
 
 type Client[T matrix.Elem] struct {
-	prg *rand.BufPRGReader
+	prg matrix.IoRandSource // secrets and errors
 
 	params *lwe.Params
 	dbinfo *DBInfo
@@ -21,9 +24,29 @@ func NewClient[T matrix.Elem](hint *matrix.Matrix[T], matrixAseed *rand.PRGKey,
 	return NewClientDistributed(hint, []rand.PRGKey{*matrixAseed}, []uint64{dbinfo.M}, dbinfo)
 }
 
+// Draws the client's secrets and errors from 'key' rather than at random,
+// so that its queries can be replayed, e.g. in tests. Never reuse a key
+// outside of tests: queries made with the same key reveal their indices.
+func NewClientWithKey[T matrix.Elem](hint *matrix.Matrix[T], matrixAseed *rand.PRGKey, dbinfo *DBInfo, key *rand.PRGKey) *Client[T] {
+	return NewClientWithSource(hint, matrixAseed, dbinfo, rand.NewBufPRG(rand.NewPRG(key)))
+}
+
+// Draws the client's secrets and errors from 'src'. The source must be
+// cryptographically secure and never replayed outside of tests: queries
+// drawn from the same stream reveal their indices.
+func NewClientWithSource[T matrix.Elem](hint *matrix.Matrix[T], matrixAseed *rand.PRGKey, dbinfo *DBInfo, src matrix.IoRandSource) *Client[T] {
+	return NewClientDistributedWithSource(hint, []rand.PRGKey{*matrixAseed}, []uint64{dbinfo.M}, dbinfo, src)
+}
+
 func NewClientDistributed[T matrix.Elem](hint *matrix.Matrix[T], matrixAseeds []rand.PRGKey, matrixArows []uint64, dbinfo *DBInfo) *Client[T] {
+	return NewClientDistributedWithSource(hint, matrixAseeds, matrixArows, dbinfo, rand.NewRandomBufPRG())
+}
+
+// As NewClientWithSource, for a matrix A split into seeded blocks of
+// 'matrixArows' rows. The same warning applies to 'src'.
+func NewClientDistributedWithSource[T matrix.Elem](hint *matrix.Matrix[T], matrixAseeds []rand.PRGKey, matrixArows []uint64, dbinfo *DBInfo, src matrix.IoRandSource) *Client[T] {
 	c := &Client[T]{
-		prg: rand.NewRandomBufPRG(),
+		prg: src,
 
 		params: dbinfo.Params,
 		dbinfo: dbinfo,
@@ -53,6 +76,9 @@ func (c *Client[T]) PreprocessQuery() *Secret[T] {
 	return c.PreprocessQueryGivenSecret(inSecret)
 }
 
+// The reuse checks only cover the returned Secret: never pass the same
+// secret matrix twice, as two queries under one secret leak the difference
+// between their indices.
 func (c *Client[T]) PreprocessQueryGivenSecret(inSecret *matrix.Matrix[T]) *Secret[T] {
 	s := &Secret[T]{
 		secret: inSecret,
@@ -76,8 +102,9 @@ func (c *Client[T]) PreprocessQueryGivenSecret(inSecret *matrix.Matrix[T]) *Secr
 	query.Add(err)
 
 	// Pad the query to match the dimensions of the compressed DB
-	if c.dbinfo.M%c.dbinfo.Squishing != 0 {
-		query.AppendZeros(c.dbinfo.Squishing - (c.dbinfo.M % c.dbinfo.Squishing))
+	squishing := c.dbinfo.Squishing
+	if squishing > 1 && c.dbinfo.M%squishing != 0 {
+		query.AppendZeros(squishing - (c.dbinfo.M % squishing))
 	}
 
 	s.query = query
@@ -85,10 +112,26 @@ func (c *Client[T]) PreprocessQueryGivenSecret(inSecret *matrix.Matrix[T]) *Secr
 	return s
 }
 
+// Panics with ErrSecretReused if 's' was already used; see
+// QueryPreprocessedChecked.
 func (c *Client[T]) QueryPreprocessed(i uint64, s *Secret[T]) *Query[T] {
+	q, err := c.QueryPreprocessedChecked(i, s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Returns ErrSecretReused if 's' was already used, e.g. for secrets handed
+// out to callers that cannot be trusted to use them once.
+func (c *Client[T]) QueryPreprocessedChecked(i uint64, s *Secret[T]) (*Query[T], error) {
+	if err := s.state.checkQuery(); err != nil {
+		return nil, err
+	}
+	s.state = SecretQueried
 	s.index = i
 	s.query.AddAt(i%c.dbinfo.M, 0, T(c.params.Delta))
-	return &Query[T]{s.query}
+	return &Query[T]{Query: s.query, Version: c.dbinfo.Version}, nil
 }
 
 func (c *Client[T]) Query(i uint64) (*Secret[T], *Query[T]) {
@@ -113,20 +156,54 @@ func (c *Client[T]) Decode(ans *matrix.Matrix[T], index uint64) uint64 {
 }
 
 func (c *Client[T]) Recover(s *Secret[T], ansIn *Answer[T]) uint64 {
+	return c.Decode(c.unmask(s, ansIn), s.index)
+}
+
+// Returns ErrSecretNotQueried if 's' was not used for a query yet, or an
+// error if the answer or the index queried does not match the database.
+func (c *Client[T]) RecoverChecked(s *Secret[T], ansIn *Answer[T]) (uint64, error) {
+	if err := c.checkRecover(s, ansIn); err != nil {
+		return 0, err
+	}
+	return c.Recover(s, ansIn), nil
+}
+
+// The checks of RecoverChecked, shared with RecoverStateless.
+func (c *Client[T]) checkRecover(s *Secret[T], ansIn *Answer[T]) error {
+	if err := s.state.checkRecover(); err != nil {
+		return err
+	}
+	if ansIn == nil || ansIn.Answer == nil ||
+		ansIn.Answer.Rows() != c.dbinfo.L || ansIn.Answer.Cols() != 1 {
+		return errors.New("pir: answer does not match the database")
+	}
+	if s.index >= c.dbinfo.Num || (s.index/c.dbinfo.M+1)*c.dbinfo.Ne > c.dbinfo.L {
+		return fmt.Errorf("pir: index %d out of range", s.index)
+	}
+	return nil
+}
+
+// Removes H * s from the answer, leaving the noisy column of the database.
+func (c *Client[T]) unmask(s *Secret[T], ansIn *Answer[T]) *matrix.Matrix[T] {
+	s.state = s.state.markRecovered()
+
 	if s.interm == nil {
+		if c.hint == nil {
+			panic("Client has no hint; use RecoverStateless")
+		}
 		s.interm = matrix.Mul(c.hint, s.secret)
 	}
 
 	ans := ansIn.Answer.Copy()
 	ans.Sub(s.interm)
 
-	return c.Decode(ans, s.index)
+	return ans
 }
 
 func (c *Client[T]) DecodeMany(ans *matrix.Matrix[T]) []uint64 {
 	num_values := (ans.Rows() / c.dbinfo.Ne)
 	out := make([]uint64, num_values)
-	for row := uint64(0); row < ans.Rows(); row += c.dbinfo.Ne {
+	for row := uint64(0); row+c.dbinfo.Ne <= ans.Rows(); row += c.dbinfo.Ne {
 		var vals []uint64
 		// Recover each Z_p element that makes up the desired database entry
 		for j := uint64(0); j < c.dbinfo.Ne; j++ {
@@ -135,22 +212,38 @@ func (c *Client[T]) DecodeMany(ans *matrix.Matrix[T]) []uint64 {
 			vals = append(vals, denoised)
 		}
 
-		out[row] = c.dbinfo.ReconstructElem(vals, 0)
-		//log.Printf("Reconstructing row %d: %d\n", row, out[row])
+		out[row/c.dbinfo.Ne] = c.dbinfo.ReconstructElem(vals, 0)
+		//log.Printf("Reconstructing row %d: %d\n", row, out[row/c.dbinfo.Ne])
 	}
 
 	return out
 }
 
 func (c *Client[T]) RecoverMany(s *Secret[T], ansIn *Answer[T]) []uint64 {
-	if s.interm == nil {
-		s.interm = matrix.Mul(c.hint, s.secret)
-	}
+	return c.DecodeMany(c.unmask(s, ansIn))
+}
 
-	ans := ansIn.Answer.Copy()
-	ans.Sub(s.interm)
+type Record struct {
+	Index uint64
+	Value uint64
+}
 
-	return c.DecodeMany(ans)
+// Returns every record in the queried column (index % M), in increasing
+// order of index.
+func (c *Client[T]) RecoverColumn(s *Secret[T], ansIn *Answer[T]) []Record {
+	vals := c.RecoverMany(s, ansIn)
+	col := s.index % c.dbinfo.M
+
+	var out []Record
+	for row, val := range vals {
+		index := uint64(row)*c.dbinfo.M + col
+		if index >= c.dbinfo.Num {
+			break
+		}
+		out = append(out, Record{Index: index, Value: val})
+	}
+
+	return out
 }
 
 func (c *Client[T]) GetM() uint64 {
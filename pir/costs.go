@@ -0,0 +1,63 @@
+package pir
+
+import (
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+// Predicted communication (in bytes) and computation (in multiply-adds)
+// of a single query.
+type Costs struct {
+	HintBytes   uint64
+	QueryBytes  uint64
+	AnswerBytes uint64
+
+	ServerOps uint64 // to answer a query
+	ClientOps uint64 // to preprocess a query (A * s and H * s)
+}
+
+func (Info *DBInfo) Costs() *Costs {
+	elemSz := Info.Params.Logq / 8
+
+	// Queries are padded to match the dimensions of the compressed DB
+	query := Info.M
+	ratio := Info.Squishing
+	if ratio == 0 {
+		ratio = squishRatio(Info.Params.Logq, Info.P())
+	}
+	if query%ratio != 0 {
+		query += ratio - (query % ratio)
+	}
+
+	return &Costs{
+		HintBytes:   Info.L * Info.Params.N * elemSz,
+		QueryBytes:  query * elemSz,
+		AnswerBytes: Info.L * elemSz,
+		ServerOps:   Info.L * Info.M,
+		ClientOps:   (Info.M + Info.L) * Info.Params.N,
+	}
+}
+
+// Estimates the costs of a database of 'num' entries of 'rowLength' bits
+// each, with the parameters chosen by NewDBInfo.
+func EstimateCosts(num, rowLength, logq uint64) *Costs {
+	return NewDBInfo(logq, num, rowLength).Costs()
+}
+
+func (c *Costs) OnlineBytes() uint64 {
+	return c.QueryBytes + c.AnswerBytes
+}
+
+func squishRatio(logq, p uint64) uint64 {
+	var params matrix.SquishParams
+	var ok bool
+	if logq == 64 {
+		params, ok = matrix.ChooseSquishParams[matrix.Elem64](p)
+	} else {
+		params, ok = matrix.ChooseSquishParams[matrix.Elem32](p)
+	}
+
+	if !ok {
+		return 1
+	}
+	return params.Ratio
+}
@@ -0,0 +1,42 @@
+package pir
+
+import (
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func testCosts[T matrix.Elem](t *testing.T, N uint64, d uint64) {
+	elemSz := T(0).Bitlen() / 8
+	costs := EstimateCosts(N, d, T(0).Bitlen())
+
+	prg := rand.NewRandomBufPRG()
+	db := NewDatabaseRandom[T](prg, N, d)
+	server := NewServer(db)
+	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+
+	_, query := client.Query(0)
+	answer := server.Answer(query)
+
+	if got := server.Hint().Size() * elemSz; got != costs.HintBytes {
+		t.Fatalf("Predicted hint of %d bytes, got %d", costs.HintBytes, got)
+	}
+	if got := query.Query.Size() * elemSz; got != costs.QueryBytes {
+		t.Fatalf("Predicted query of %d bytes, got %d", costs.QueryBytes, got)
+	}
+	if got := answer.Answer.Size() * elemSz; got != costs.AnswerBytes {
+		t.Fatalf("Predicted answer of %d bytes, got %d", costs.AnswerBytes, got)
+	}
+	if costs.ServerOps != db.Info.L*db.Info.M {
+		t.Fail()
+	}
+}
+
+func TestCosts32(t *testing.T) {
+	testCosts[matrix.Elem32](t, uint64(1<<16)+7, uint64(8))
+}
+
+func TestCosts64(t *testing.T) {
+	testCosts[matrix.Elem64](t, uint64(1<<14), uint64(40))
+}
@@ -0,0 +1,90 @@
+package pir
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/ryanleh/simplepir/rand"
+)
+
+const cuckooHashes = 3
+
+// Maximum number of evictions before giving up on an insertion.
+const cuckooMaxEvictions = 500
+
+// Maps each item to cuckooHashes distinct candidate buckets, using hash
+// functions derived from a public key.
+type cuckooHasher struct {
+	key        rand.PRGKey
+	numBuckets uint64
+}
+
+func newCuckooHasher(key *rand.PRGKey, numBuckets uint64) *cuckooHasher {
+	if numBuckets < cuckooHashes {
+		panic("Too few buckets")
+	}
+	return &cuckooHasher{key: *key, numBuckets: numBuckets}
+}
+
+func (h *cuckooHasher) buckets(item uint64) []uint64 {
+	var in [len(rand.PRGKey{}) + 16]byte
+	copy(in[:], h.key[:])
+	binary.LittleEndian.PutUint64(in[len(h.key):], item)
+
+	out := make([]uint64, 0, cuckooHashes)
+	for ctr := uint64(0); len(out) < cuckooHashes; ctr++ {
+		binary.LittleEndian.PutUint64(in[len(h.key)+8:], ctr)
+		sum := sha256.Sum256(in[:])
+		b := binary.LittleEndian.Uint64(sum[:]) % h.numBuckets
+
+		dup := false
+		for _, o := range out {
+			dup = dup || (o == b)
+		}
+		if !dup {
+			out = append(out, b)
+		}
+	}
+
+	return out
+}
+
+// Places every item in one of its candidate buckets, with at most one item
+// per bucket, evicting occupants along a random walk. Returns the item held
+// by each bucket, or false if the insertion failed.
+func (h *cuckooHasher) insert(items []uint64, prg *rand.BufPRGReader) (map[uint64]uint64, bool) {
+	table := make(map[uint64]uint64)
+	for _, item := range items {
+		cur := item
+		last := h.numBuckets // bucket 'cur' was just evicted from
+		placed := false
+
+		for tries := 0; tries < cuckooMaxEvictions; tries++ {
+			cands := h.buckets(cur)
+			for _, b := range cands {
+				if _, full := table[b]; !full {
+					table[b] = cur
+					placed = true
+					break
+				}
+			}
+			if placed {
+				break
+			}
+
+			// Evict the occupant of another candidate bucket
+			b := cands[prg.Uint64()%cuckooHashes]
+			for b == last {
+				b = cands[prg.Uint64()%cuckooHashes]
+			}
+			table[b], cur = cur, table[b]
+			last = b
+		}
+
+		if !placed {
+			return nil, false
+		}
+	}
+
+	return table, true
+}
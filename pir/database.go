@@ -24,9 +24,12 @@ type DBInfo struct {
 	M uint64 // database width
 
 	// For in-memory db compression
-	Squishing uint64
+	Squishing uint64 // values packed per element; set to 1 to disable
+	Basis     uint64 // bits per packed value
 	Cols      uint64
 
+	Version uint64 // of the database contents, see VersionedServer
+
 	Params *lwe.Params
 }
 
@@ -46,14 +49,32 @@ func (db *Database[T]) Squish() {
 	//log.Printf("Original db dims: ")
 	//db.Data.Dim()
 
-	// Check that Params allow for this compression
-	if !db.Data.CanSquish(db.Info.P()) {
-		panic("Bad Params")
+	// Pack as many Z_p elements as possible into each element, unless
+	// disabled or P is too large for every layout.
+	params, ok := matrix.ChooseSquishParams[T](db.Info.P())
+	if !ok || db.Info.Squishing == 1 {
+		db.Info.Squishing = 1
+		db.Info.Basis = 0
+		db.Info.Cols = db.Data.Cols()
+		return
 	}
 
-	db.Info.Squishing = db.Data.SquishRatio()
+	db.Info.Squishing = params.Ratio
+	db.Info.Basis = params.Basis
 	db.Info.Cols = db.Data.Cols()
-	db.Data.Squish()
+	db.Data.SquishWith(params)
+}
+
+func (Info *DBInfo) SquishParams() matrix.SquishParams {
+	basis := Info.Basis
+	if basis == 0 {
+		// Databases squished before the basis was recorded
+		basis = (&matrix.Matrix[matrix.Elem64]{}).SquishBasis()
+		if Info.Params.Logq == 32 {
+			basis = (&matrix.Matrix[matrix.Elem32]{}).SquishBasis()
+		}
+	}
+	return matrix.SquishParams{Basis: basis, Ratio: Info.Squishing}
 }
 
 // Store the database with entries decomposed into Z_p elements.
@@ -92,11 +113,7 @@ func (db *Database[T]) GetElem(i uint64) uint64 {
 // Returns how many Z_p elements are needed to represent a database of N entries,
 // each consisting of row_length bits.
 func numEntries(N, row_length, p uint64) (uint64, uint64) {
-	if float64(row_length) <= math.Log2(float64(p)) {
-		return N, 1
-	}
-
-	// use multiple Z_p elems to represent a single DB entry
+	// use multiple Z_p elems to represent a single DB entry, if needed
 	ne := Compute_num_entries_base_p(p, row_length)
 	return N * ne, ne
 }
@@ -134,6 +151,9 @@ func NewDBInfo(logq uint64, num uint64, rowLength uint64) *DBInfo {
 }
 
 func NewDBInfoFixedParams(num uint64, rowLength uint64, params *lwe.Params, fixed bool) *DBInfo {
+	if (num == 0) || (rowLength == 0) {
+		panic("Empty database!")
+	}
 	Info := &DBInfo{
 		Num:       num,
 		RowLength: rowLength,
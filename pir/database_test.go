@@ -1,8 +1,10 @@
 package pir
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/ryanleh/simplepir/lwe"
 	"github.com/ryanleh/simplepir/matrix"
 )
 
@@ -73,3 +75,85 @@ func TestDBLargeEntries32(t *testing.T) {
 func TestDBLargeEntries64(t *testing.T) {
 	testDBLargeEntries[matrix.Elem64](t)
 }
+
+// Entries decompose into base-p digits and back, using as few digits as
+// possible.
+func fuzzBaseP[T matrix.Elem](f *testing.F) {
+	f.Add(uint64(2), uint64(0))
+	f.Add(uint64(991), uint64(1<<63-1))
+	f.Add(uint64(1<<32), uint64(1<<32-1))
+	f.Add(uint64(1<<32-1), uint64(1<<64-1))
+	f.Add(uint64(1<<64-1), uint64(1<<64-1)) // log2(p) rounds to 64
+
+	f.Fuzz(func(t *testing.T, p uint64, m uint64) {
+		p = max(uint64(T(p)), 2)
+		ne := Compute_num_entries_base_p(p, T(0).Bitlen())
+
+		q := new(big.Int).Lsh(big.NewInt(1), uint(T(0).Bitlen()))
+		pow := new(big.Int).Exp(new(big.Int).SetUint64(p), new(big.Int).SetUint64(ne-1), nil)
+		if pow.Cmp(q) >= 0 || pow.Mul(pow, new(big.Int).SetUint64(p)).Cmp(q) < 0 {
+			t.Fatalf("%d digits base %d for %d bits", ne, p, T(0).Bitlen())
+		}
+
+		digits := make([]uint64, ne)
+		for j := range digits {
+			digits[j] = uint64(Base_p(T(p), T(m), uint64(j)))
+			if digits[j] >= p {
+				t.Fatalf("Digit %d of %d is %d", j, T(m), digits[j])
+			}
+		}
+		if got := Reconstruct_from_base_p(p, digits); got != uint64(T(m)) {
+			t.Fatalf("Reconstructed %d instead of %d", got, T(m))
+		}
+	})
+}
+
+func FuzzBaseP32(f *testing.F) {
+	fuzzBaseP[matrix.Elem32](f)
+}
+
+func FuzzBaseP64(f *testing.F) {
+	fuzzBaseP[matrix.Elem64](f)
+}
+
+// The database is as small as possible given its width, and each record
+// takes as few Z_p elements as possible.
+func FuzzDBInfo(f *testing.F) {
+	f.Add(uint32(1), uint16(1), uint64(2), uint16(1))
+	f.Add(uint32(1000), uint16(8), uint64(256), uint16(100))       // rowLength = log2(p)
+	f.Add(uint32(1000), uint16(9), uint64(256), uint16(100))       // just over
+	f.Add(uint32(1<<20+7), uint16(32), uint64(991), uint16(1<<13)) // Num % M != 0
+
+	f.Fuzz(func(t *testing.T, num uint32, rowLength uint16, p uint64, m uint16) {
+		n := uint64(num) + 1
+		d := uint64(rowLength)%1024 + 1
+		params := &lwe.Params{Logq: 32, P: p%(1<<32) + 2, M: uint64(m) + 1}
+
+		info := NewDBInfoFixedParams(n, d, params, true)
+
+		bound := new(big.Int).Lsh(big.NewInt(1), uint(d))
+		pow := new(big.Int).Exp(new(big.Int).SetUint64(params.P), new(big.Int).SetUint64(info.Ne-1), nil)
+		if pow.Cmp(bound) >= 0 || pow.Mul(pow, new(big.Int).SetUint64(params.P)).Cmp(bound) < 0 {
+			t.Fatalf("%d elements base %d for %d bits", info.Ne, params.P, d)
+		}
+
+		elems := n * info.Ne
+		if info.M != params.M || info.L%info.Ne != 0 || info.L*info.M < elems ||
+			(info.L-info.Ne)*info.M >= elems {
+			t.Fatalf("%d-by-%d database for %d elements of %d", info.L, info.M, n, info.Ne)
+		}
+
+		l, m2 := approxSquareDatabaseDims(elems, info.Ne, d, params.P)
+		if l == 0 || l%info.Ne != 0 || l*m2 < elems {
+			t.Fatalf("%d-by-%d square database for %d elements of %d", l, m2, n, info.Ne)
+		}
+	})
+}
+
+// Empty databases are rejected even with fixed params, rather than dividing
+// by zero entries per record.
+func TestDBEmptyFixedParams(t *testing.T) {
+	params := lwe.NewParamsFixedP(32, 1<<10, 256)
+	expectPanic(t, "Empty database!", func() { NewDBInfoFixedParams(4, 0, params, true) })
+	expectPanic(t, "Empty database!", func() { NewDBInfoFixedParams(0, 8, params, true) })
+}
@@ -0,0 +1,134 @@
+package pirgrpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+)
+
+type Client[T matrix.Elem] struct {
+	rpc    PIRClient
+	client *pir.Client[T]
+	info   *pir.DBInfo
+}
+
+// Fetches the params and downloads the hint, verifying every chunk.
+func NewClient[T matrix.Elem](ctx context.Context, conn grpc.ClientConnInterface) (*Client[T], error) {
+	rpc := NewPIRClient(conn)
+	params, err := rpc.GetParams(ctx, &GetParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := decodeManifest(params, T(0).Bitlen())
+	if err != nil {
+		return nil, fmt.Errorf("bad params: %w", err)
+	}
+	builder, err := pir.NewHintBuilder[T](m)
+	if err != nil {
+		return nil, fmt.Errorf("bad params: %w", err)
+	}
+	stream, err := rpc.StreamHint(ctx, &StreamHintRequest{})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := builder.AddChunk(int(chunk.Index), chunk.Data); err != nil {
+			return nil, err
+		}
+	}
+	client, err := builder.Client()
+	if err != nil {
+		return nil, fmt.Errorf("hint download: %w", err)
+	}
+
+	return &Client[T]{
+		rpc:    rpc,
+		client: client,
+		info:   m.Info,
+	}, nil
+}
+
+// The underlying client, e.g. to preprocess queries.
+func (c *Client[T]) PIR() *pir.Client[T] {
+	return c.client
+}
+
+func (c *Client[T]) DBInfo() *pir.DBInfo {
+	return c.info
+}
+
+func (c *Client[T]) Get(ctx context.Context, index uint64) (uint64, error) {
+	if index >= c.info.Num {
+		return 0, fmt.Errorf("index %d out of range", index)
+	}
+
+	secret, query := c.client.Query(index)
+	res, err := c.rpc.Answer(ctx, &AnswerRequest{Query: encodeMatrix(query.Query), Version: query.Version})
+	if err != nil {
+		return 0, err
+	}
+	if err := c.checkVersion(res.Version); err != nil {
+		return 0, err
+	}
+
+	ans, err := decodeMatrix[T](res.Answer, c.info.L, 1)
+	if err != nil {
+		return 0, fmt.Errorf("bad answer: %w", err)
+	}
+	return c.client.Recover(secret, &pir.Answer[T]{Answer: ans}), nil
+}
+
+// Retrieves several records in one round trip.
+func (c *Client[T]) GetBatch(ctx context.Context, indices []uint64) ([]uint64, error) {
+	secrets := make([]*pir.Secret[T], len(indices))
+	req := &AnswerBatchRequest{Queries: make([]*Matrix, len(indices)), Version: c.info.Version}
+	for i, index := range indices {
+		if index >= c.info.Num {
+			return nil, fmt.Errorf("index %d out of range", index)
+		}
+		var query *pir.Query[T]
+		secrets[i], query = c.client.Query(index)
+		req.Queries[i] = encodeMatrix(query.Query)
+	}
+
+	res, err := c.rpc.AnswerBatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkVersion(res.Version); err != nil {
+		return nil, err
+	}
+	if len(res.Answers) != len(indices) {
+		return nil, fmt.Errorf("got %d answers for %d queries", len(res.Answers), len(indices))
+	}
+
+	out := make([]uint64, len(indices))
+	for i, a := range res.Answers {
+		ans, err := decodeMatrix[T](a, c.info.L, 1)
+		if err != nil {
+			return nil, fmt.Errorf("bad answer %d: %w", i, err)
+		}
+		out[i] = c.client.Recover(secrets[i], &pir.Answer[T]{Answer: ans})
+	}
+	return out, nil
+}
+
+func (c *Client[T]) checkVersion(version uint64) error {
+	if version != c.info.Version {
+		return fmt.Errorf("answered by database version %d, expected %d", version, c.info.Version)
+	}
+	return nil
+}
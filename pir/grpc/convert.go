@@ -0,0 +1,167 @@
+package pirgrpc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ryanleh/simplepir/lwe"
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+)
+
+func encodeManifest(m *pir.HintManifest) *Params {
+	p := &Params{
+		Info: &DBInfo{
+			Num:       m.Info.Num,
+			RowLength: m.Info.RowLength,
+			Ne:        m.Info.Ne,
+			X:         m.Info.X,
+			L:         m.Info.L,
+			M:         m.Info.M,
+			Squishing: m.Info.Squishing,
+			Basis:     m.Info.Basis,
+			Cols:      m.Info.Cols,
+			Version:   m.Info.Version,
+		},
+		Lwe: &LWEParams{
+			N:     m.Params.N,
+			Sigma: m.Params.Sigma,
+			M:     m.Params.M,
+			Logq:  m.Params.Logq,
+			P:     m.Params.P,
+			Delta: m.Params.Delta,
+		},
+		Seed:     append([]byte(nil), m.Seed[:]...),
+		HintRows: m.Rows,
+		HintCols: m.Cols,
+	}
+
+	for _, c := range m.Chunks {
+		p.Chunks = append(p.Chunks, &HintChunkInfo{
+			Start:  c.Start,
+			Rows:   c.Rows,
+			Digest: append([]byte(nil), c.Digest[:]...),
+		})
+	}
+	return p
+}
+
+// Largest hint NewClient downloads, so that a server cannot make the client
+// allocate arbitrary memory. Raise it for larger databases.
+var MaxHintBytes uint64 = 1 << 32
+
+// Checks what the client relies on: the params must be sound for
+// 'logq'-bit elements, the shapes must be consistent and the hint at most
+// MaxHintBytes, and the chunks must tile the hint, so that a malicious
+// server can only make the client fail, not panic.
+func decodeManifest(p *Params, logq uint64) (*pir.HintManifest, error) {
+	if p.Info == nil || p.Lwe == nil {
+		return nil, fmt.Errorf("missing DBInfo or LWE params")
+	}
+
+	m := &pir.HintManifest{
+		Info: &pir.DBInfo{
+			Num:       p.Info.Num,
+			RowLength: p.Info.RowLength,
+			Ne:        p.Info.Ne,
+			X:         p.Info.X,
+			L:         p.Info.L,
+			M:         p.Info.M,
+			Squishing: p.Info.Squishing,
+			Basis:     p.Info.Basis,
+			Cols:      p.Info.Cols,
+			Version:   p.Info.Version,
+		},
+		Params: &lwe.Params{
+			N:     p.Lwe.N,
+			Sigma: p.Lwe.Sigma,
+			M:     p.Lwe.M,
+			Logq:  p.Lwe.Logq,
+			P:     p.Lwe.P,
+			Delta: p.Lwe.Delta,
+		},
+		Rows: p.HintRows,
+		Cols: p.HintCols,
+	}
+	m.Info.Params = m.Params
+
+	if len(p.Seed) != len(m.Seed) {
+		return nil, fmt.Errorf("seed has %d bytes, expected %d", len(p.Seed), len(m.Seed))
+	}
+	copy(m.Seed[:], p.Seed)
+
+	// Query privacy rests on N and Sigma, and recovery on P and Delta: all
+	// must be what lwe picks for this many samples
+	if m.Params.Logq != logq {
+		return nil, fmt.Errorf("server uses %d-bit elements, expected %d", m.Params.Logq, logq)
+	}
+	if m.Params.P < 2 {
+		return nil, fmt.Errorf("bad LWE params: p=%d", m.Params.P)
+	}
+	want := lwe.NewParamsFixedP(logq, m.Params.M, m.Params.P)
+	if want == nil || *m.Params != *want {
+		return nil, fmt.Errorf("bad LWE params: n=%d, sigma=%g, m=%d, p=%d, delta=%d",
+			m.Params.N, m.Params.Sigma, m.Params.M, m.Params.P, m.Params.Delta)
+	}
+	if m.Info.M > m.Params.M {
+		return nil, fmt.Errorf("bad LWE params: %d samples for a database of width %d", m.Params.M, m.Info.M)
+	}
+
+	for i, c := range p.Chunks {
+		if len(c.Digest) != sha256.Size {
+			return nil, fmt.Errorf("chunk %d has a %d-byte digest", i, len(c.Digest))
+		}
+		info := pir.HintChunkInfo{Start: c.Start, Rows: c.Rows}
+		copy(info.Digest[:], c.Digest)
+		m.Chunks = append(m.Chunks, info)
+	}
+	if err := m.Validate(logq); err != nil {
+		return nil, err
+	}
+	if size := m.Rows * m.Cols * (logq / 8); size > MaxHintBytes {
+		return nil, fmt.Errorf("%d-byte hint, at most %d allowed", size, MaxHintBytes)
+	}
+
+	return m, nil
+}
+
+func encodeMatrix[T matrix.Elem](a *matrix.Matrix[T]) *Matrix {
+	elemSz := T(0).Bitlen() / 8
+	data := a.Data()
+	buf := make([]byte, uint64(len(data))*elemSz)
+	for i, v := range data {
+		if elemSz == 4 {
+			binary.LittleEndian.PutUint32(buf[uint64(i)*elemSz:], uint32(v))
+		} else {
+			binary.LittleEndian.PutUint64(buf[uint64(i)*elemSz:], uint64(v))
+		}
+	}
+
+	return &Matrix{Rows: a.Rows(), Cols: a.Cols(), Data: buf}
+}
+
+// Decodes a matrix of the expected shape.
+func decodeMatrix[T matrix.Elem](m *Matrix, rows, cols uint64) (*matrix.Matrix[T], error) {
+	if m == nil {
+		return nil, fmt.Errorf("missing matrix")
+	}
+	if m.Rows != rows || m.Cols != cols {
+		return nil, fmt.Errorf("matrix is %d-by-%d, expected %d-by-%d", m.Rows, m.Cols, rows, cols)
+	}
+
+	elemSz := T(0).Bitlen() / 8
+	if uint64(len(m.Data)) != rows*cols*elemSz {
+		return nil, fmt.Errorf("matrix has %d bytes, expected %d", len(m.Data), rows*cols*elemSz)
+	}
+
+	data := make([]T, rows*cols)
+	for i := range data {
+		if elemSz == 4 {
+			data[i] = T(binary.LittleEndian.Uint32(m.Data[uint64(i)*elemSz:]))
+		} else {
+			data[i] = T(binary.LittleEndian.Uint64(m.Data[uint64(i)*elemSz:]))
+		}
+	}
+	return matrix.NewFromRaw(data, rows, cols), nil
+}
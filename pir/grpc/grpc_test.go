@@ -0,0 +1,289 @@
+package pirgrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+// As in package pir: randomness seeded from PIR_SEED (in hex) if set, and
+// logged if the test fails.
+func testPRG(t testing.TB) *rand.BufPRGReader {
+	key := rand.RandomPRGKey()
+	if env := os.Getenv("PIR_SEED"); env != "" {
+		b, err := hex.DecodeString(env)
+		if err != nil || len(b) != len(key) {
+			t.Fatalf("PIR_SEED must be %d hex-encoded bytes", len(key))
+		}
+		copy(key[:], b)
+	}
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("PIR_SEED=%x", key[:])
+		}
+	})
+	return rand.NewBufPRG(rand.NewPRG(key))
+}
+
+func testKey(prg *rand.BufPRGReader) *rand.PRGKey {
+	var key rand.PRGKey
+	if _, err := io.ReadFull(prg, key[:]); err != nil {
+		panic(err)
+	}
+	return &key
+}
+
+// Serves 'server' over an in-memory connection.
+func dial[T matrix.Elem](t *testing.T, server *pir.Server[T], chunkBytes uint64) *grpc.ClientConn {
+	return listen(t, NewService(server, chunkBytes))
+}
+
+func listen(t *testing.T, service PIRServer) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterPIRServer(s, service)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func testGRPC[T matrix.Elem](t *testing.T, d uint64) {
+	ctx := context.Background()
+	prg := testPRG(t)
+	db := pir.NewDatabaseRandom[T](prg, 1<<16, d)
+	db.Info.Version = 3
+	server := pir.NewServerSeed(db, testKey(prg))
+
+	// Small chunks, so that the hint is streamed in several
+	hintBytes := server.Hint().Rows() * server.Hint().Cols() * (T(0).Bitlen() / 8)
+	conn := dial(t, server, hintBytes/5)
+
+	client, err := NewClient[T](ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := client.DBInfo().Version; v != 3 {
+		t.Fatalf("got database version %d, expected 3", v)
+	}
+
+	for _, i := range []uint64{0, 1, 1<<16 - 1} {
+		val, err := client.Get(ctx, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val != db.GetElem(i) {
+			t.Fatalf("record %d: got %d, expected %d", i, val, db.GetElem(i))
+		}
+	}
+
+	indices := []uint64{5, 700, 5, 4000}
+	vals, err := client.GetBatch(ctx, indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, i := range indices {
+		if vals[j] != db.GetElem(i) {
+			t.Fatalf("batch record %d: got %d, expected %d", i, vals[j], db.GetElem(i))
+		}
+	}
+
+	if _, err := client.Get(ctx, 1<<16); err == nil {
+		t.Fatal("expected error for an index out of range")
+	}
+
+	// Malformed queries are rejected
+	rpc := NewPIRClient(conn)
+	bad := encodeMatrix(matrix.Zeros[T](3, 1))
+	_, err = rpc.Answer(ctx, &AnswerRequest{Query: bad, Version: 3})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+
+	// So are queries for another version
+	_, query := client.PIR().Query(0)
+	_, err = rpc.Answer(ctx, &AnswerRequest{Query: encodeMatrix(query.Query), Version: 2})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	_, err = rpc.AnswerBatch(ctx, &AnswerBatchRequest{Queries: []*Matrix{encodeMatrix(query.Query)}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+
+	// Resuming sends only the chunks asked for
+	stream, err := rpc.StreamHint(ctx, &StreamHintRequest{Chunks: []uint32{2, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []uint32{2, 0} {
+		chunk, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunk.Index != want {
+			t.Fatalf("got chunk %d, expected %d", chunk.Index, want)
+		}
+	}
+}
+
+// Answers as if from the next database version.
+type nextVersion[T matrix.Elem] struct {
+	*Service[T]
+}
+
+func (s nextVersion[T]) Answer(ctx context.Context, req *AnswerRequest) (*AnswerResponse, error) {
+	res, err := s.Service.Answer(ctx, req)
+	if err == nil {
+		res.Version++
+	}
+	return res, err
+}
+
+func (s nextVersion[T]) AnswerBatch(ctx context.Context, req *AnswerBatchRequest) (*AnswerBatchResponse, error) {
+	res, err := s.Service.AnswerBatch(ctx, req)
+	if err == nil {
+		res.Version++
+	}
+	return res, err
+}
+
+// The client rejects answers from a version other than its hint's.
+func testWrongVersion[T matrix.Elem](t *testing.T, d uint64) {
+	ctx := context.Background()
+	prg := testPRG(t)
+	db := pir.NewDatabaseRandom[T](prg, 1<<10, d)
+	server := pir.NewServerSeed(db, testKey(prg))
+	conn := listen(t, nextVersion[T]{NewService(server, DefaultChunkBytes)})
+
+	client, err := NewClient[T](ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(ctx, 1); err == nil || !strings.Contains(err.Error(), "version 1") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+	if _, err := client.GetBatch(ctx, []uint64{1, 2}); err == nil || !strings.Contains(err.Error(), "version 1") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
+
+func TestWrongVersion32(t *testing.T) {
+	testWrongVersion[matrix.Elem32](t, 8)
+}
+
+func TestWrongVersion64(t *testing.T) {
+	testWrongVersion[matrix.Elem64](t, 32)
+}
+
+func TestGRPC32(t *testing.T) {
+	testGRPC[matrix.Elem32](t, 8)
+}
+
+func TestGRPC64(t *testing.T) {
+	testGRPC[matrix.Elem64](t, 32)
+}
+
+func TestBadParams(t *testing.T) {
+	prg := testPRG(t)
+	db := pir.NewDatabaseRandom[matrix.Elem32](prg, 1<<16, 8)
+	server := pir.NewServerSeed(db, testKey(prg))
+	hintBytes := server.Hint().Rows() * server.Hint().Cols() * 4
+
+	tamper := []func(p *Params){
+		func(p *Params) { p.Seed = p.Seed[1:] },
+		func(p *Params) { p.HintRows++ },
+		func(p *Params) { p.Chunks = p.Chunks[1:] },
+		func(p *Params) { p.Chunks[0].Rows++ },
+		func(p *Params) { p.Chunks[1].Digest = nil },
+		func(p *Params) { p.Info = nil },
+		func(p *Params) { p.Lwe.Delta = 0 },
+		func(p *Params) { p.Lwe.Delta++ },
+		func(p *Params) { p.Lwe.P = 1 << 20 },
+		func(p *Params) { p.Lwe.Logq = 64 },
+		func(p *Params) { p.Lwe.M = 1 << 40 },
+		func(p *Params) { p.Lwe.N = 1 },
+		func(p *Params) { p.Lwe.N-- },
+		func(p *Params) { p.Lwe.Sigma = 0 },
+		func(p *Params) { p.Lwe.P = 0 },
+		func(p *Params) { p.Lwe.P = 1000; p.Lwe.Delta = (1 << 32) / 1000 },
+		func(p *Params) { p.Info.M = p.Lwe.M + 1 },
+		func(p *Params) { p.Info.Num = p.Info.L/p.Info.Ne*p.Info.M + 1 },
+		func(p *Params) { p.Info.Num = 1 << 60 },
+		func(p *Params) { p.Info.Num = 0 },
+		func(p *Params) { p.Info.Ne = 0 },
+		func(p *Params) { p.Info.Squishing = 1 << 40 },
+		func(p *Params) { p.Info.Squishing = 5 },
+		func(p *Params) { p.Info.Basis = 9 },
+		func(p *Params) {
+			// A taller hint, consistently described, but not for Num
+			p.Info.L += p.Info.Ne
+			p.HintRows = p.Info.L
+			p.Chunks[len(p.Chunks)-1].Rows += p.Info.Ne
+		},
+		func(p *Params) {
+			// A consistent but huge database
+			p.Info.Num = 1 << 50
+			p.Info.L = p.Info.Ne * (p.Info.Num / p.Info.M)
+			p.HintRows = p.Info.L
+			p.Chunks[len(p.Chunks)-1].Rows = p.Info.L - p.Chunks[len(p.Chunks)-1].Start
+		},
+	}
+	for i, f := range tamper {
+		p := encodeManifest(server.HintManifest(hintBytes / 5))
+		f(p)
+		if _, err := decodeManifest(p, 32); err == nil {
+			t.Fatalf("tampering %d: expected error", i)
+		}
+	}
+
+	// Accepted as is, but not for the other element type
+	p := encodeManifest(server.HintManifest(hintBytes / 5))
+	if _, err := decodeManifest(p, 32); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeManifest(p, 64); err == nil {
+		t.Fatal("Accepted 32-bit params for 64-bit elements")
+	}
+
+	// A zero Delta never reaches Recover
+	p.Lwe.Delta = 0
+	conn := listen(t, fixedParams{p: p})
+	if _, err := NewClient[matrix.Elem32](context.Background(), conn); err == nil || !strings.Contains(err.Error(), "delta=0") {
+		t.Fatalf("Client accepted params with a zero Delta: %v", err)
+	}
+}
+
+// A malicious server, handing out the given params.
+type fixedParams struct {
+	UnimplementedPIRServer
+	p *Params
+}
+
+func (f fixedParams) GetParams(context.Context, *GetParamsRequest) (*Params, error) {
+	return f.p, nil
+}
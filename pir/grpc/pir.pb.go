@@ -0,0 +1,915 @@
+// gRPC contract for SimplePIR. Matrices are sent as the little-endian
+// encoding of their elements in row-major order, with 4-byte elements if
+// logq is 32 and 8-byte elements if logq is 64.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: pir.proto
+
+package pirgrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetParamsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetParamsRequest) Reset() {
+	*x = GetParamsRequest{}
+	mi := &file_pir_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetParamsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetParamsRequest) ProtoMessage() {}
+
+func (x *GetParamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetParamsRequest.ProtoReflect.Descriptor instead.
+func (*GetParamsRequest) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{0}
+}
+
+type LWEParams struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	N             uint64                 `protobuf:"varint,1,opt,name=n,proto3" json:"n,omitempty"`          // secret dimension
+	Sigma         float64                `protobuf:"fixed64,2,opt,name=sigma,proto3" json:"sigma,omitempty"` // error stddev
+	M             uint64                 `protobuf:"varint,3,opt,name=m,proto3" json:"m,omitempty"`          // samples supported
+	Logq          uint64                 `protobuf:"varint,4,opt,name=logq,proto3" json:"logq,omitempty"`
+	P             uint64                 `protobuf:"varint,5,opt,name=p,proto3" json:"p,omitempty"` // plaintext modulus
+	Delta         uint64                 `protobuf:"varint,6,opt,name=delta,proto3" json:"delta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LWEParams) Reset() {
+	*x = LWEParams{}
+	mi := &file_pir_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LWEParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LWEParams) ProtoMessage() {}
+
+func (x *LWEParams) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LWEParams.ProtoReflect.Descriptor instead.
+func (*LWEParams) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LWEParams) GetN() uint64 {
+	if x != nil {
+		return x.N
+	}
+	return 0
+}
+
+func (x *LWEParams) GetSigma() float64 {
+	if x != nil {
+		return x.Sigma
+	}
+	return 0
+}
+
+func (x *LWEParams) GetM() uint64 {
+	if x != nil {
+		return x.M
+	}
+	return 0
+}
+
+func (x *LWEParams) GetLogq() uint64 {
+	if x != nil {
+		return x.Logq
+	}
+	return 0
+}
+
+func (x *LWEParams) GetP() uint64 {
+	if x != nil {
+		return x.P
+	}
+	return 0
+}
+
+func (x *LWEParams) GetDelta() uint64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+type DBInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Num           uint64                 `protobuf:"varint,1,opt,name=num,proto3" json:"num,omitempty"`                              // number of records
+	RowLength     uint64                 `protobuf:"varint,2,opt,name=row_length,json=rowLength,proto3" json:"row_length,omitempty"` // bits per record
+	Ne            uint64                 `protobuf:"varint,3,opt,name=ne,proto3" json:"ne,omitempty"`                                // Z_p elements per record
+	X             uint64                 `protobuf:"varint,4,opt,name=x,proto3" json:"x,omitempty"`
+	L             uint64                 `protobuf:"varint,5,opt,name=l,proto3" json:"l,omitempty"` // database height
+	M             uint64                 `protobuf:"varint,6,opt,name=m,proto3" json:"m,omitempty"` // database width
+	Squishing     uint64                 `protobuf:"varint,7,opt,name=squishing,proto3" json:"squishing,omitempty"`
+	Basis         uint64                 `protobuf:"varint,8,opt,name=basis,proto3" json:"basis,omitempty"`
+	Cols          uint64                 `protobuf:"varint,9,opt,name=cols,proto3" json:"cols,omitempty"`
+	Version       uint64                 `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"` // of the database contents
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBInfo) Reset() {
+	*x = DBInfo{}
+	mi := &file_pir_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBInfo) ProtoMessage() {}
+
+func (x *DBInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBInfo.ProtoReflect.Descriptor instead.
+func (*DBInfo) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DBInfo) GetNum() uint64 {
+	if x != nil {
+		return x.Num
+	}
+	return 0
+}
+
+func (x *DBInfo) GetRowLength() uint64 {
+	if x != nil {
+		return x.RowLength
+	}
+	return 0
+}
+
+func (x *DBInfo) GetNe() uint64 {
+	if x != nil {
+		return x.Ne
+	}
+	return 0
+}
+
+func (x *DBInfo) GetX() uint64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *DBInfo) GetL() uint64 {
+	if x != nil {
+		return x.L
+	}
+	return 0
+}
+
+func (x *DBInfo) GetM() uint64 {
+	if x != nil {
+		return x.M
+	}
+	return 0
+}
+
+func (x *DBInfo) GetSquishing() uint64 {
+	if x != nil {
+		return x.Squishing
+	}
+	return 0
+}
+
+func (x *DBInfo) GetBasis() uint64 {
+	if x != nil {
+		return x.Basis
+	}
+	return 0
+}
+
+func (x *DBInfo) GetCols() uint64 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *DBInfo) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type HintChunkInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         uint64                 `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"` // first row
+	Rows          uint64                 `protobuf:"varint,2,opt,name=rows,proto3" json:"rows,omitempty"`
+	Digest        []byte                 `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"` // SHA-256 of the chunk data
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HintChunkInfo) Reset() {
+	*x = HintChunkInfo{}
+	mi := &file_pir_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HintChunkInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HintChunkInfo) ProtoMessage() {}
+
+func (x *HintChunkInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HintChunkInfo.ProtoReflect.Descriptor instead.
+func (*HintChunkInfo) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HintChunkInfo) GetStart() uint64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *HintChunkInfo) GetRows() uint64 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *HintChunkInfo) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+type Params struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *DBInfo                `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Lwe           *LWEParams             `protobuf:"bytes,2,opt,name=lwe,proto3" json:"lwe,omitempty"`
+	Seed          []byte                 `protobuf:"bytes,3,opt,name=seed,proto3" json:"seed,omitempty"` // of the A matrix
+	HintRows      uint64                 `protobuf:"varint,4,opt,name=hint_rows,json=hintRows,proto3" json:"hint_rows,omitempty"`
+	HintCols      uint64                 `protobuf:"varint,5,opt,name=hint_cols,json=hintCols,proto3" json:"hint_cols,omitempty"`
+	Chunks        []*HintChunkInfo       `protobuf:"bytes,6,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Params) Reset() {
+	*x = Params{}
+	mi := &file_pir_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Params) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Params) ProtoMessage() {}
+
+func (x *Params) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Params.ProtoReflect.Descriptor instead.
+func (*Params) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Params) GetInfo() *DBInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *Params) GetLwe() *LWEParams {
+	if x != nil {
+		return x.Lwe
+	}
+	return nil
+}
+
+func (x *Params) GetSeed() []byte {
+	if x != nil {
+		return x.Seed
+	}
+	return nil
+}
+
+func (x *Params) GetHintRows() uint64 {
+	if x != nil {
+		return x.HintRows
+	}
+	return 0
+}
+
+func (x *Params) GetHintCols() uint64 {
+	if x != nil {
+		return x.HintCols
+	}
+	return 0
+}
+
+func (x *Params) GetChunks() []*HintChunkInfo {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+type StreamHintRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Indices into Params.chunks to send, e.g. to resume a download. Empty
+	// means all of them.
+	Chunks        []uint32 `protobuf:"varint,1,rep,packed,name=chunks,proto3" json:"chunks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamHintRequest) Reset() {
+	*x = StreamHintRequest{}
+	mi := &file_pir_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamHintRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamHintRequest) ProtoMessage() {}
+
+func (x *StreamHintRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamHintRequest.ProtoReflect.Descriptor instead.
+func (*StreamHintRequest) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StreamHintRequest) GetChunks() []uint32 {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+type HintChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // into Params.chunks
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HintChunk) Reset() {
+	*x = HintChunk{}
+	mi := &file_pir_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HintChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HintChunk) ProtoMessage() {}
+
+func (x *HintChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HintChunk.ProtoReflect.Descriptor instead.
+func (*HintChunk) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *HintChunk) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *HintChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Matrix struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          uint64                 `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols          uint64                 `protobuf:"varint,2,opt,name=cols,proto3" json:"cols,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Matrix) Reset() {
+	*x = Matrix{}
+	mi := &file_pir_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Matrix) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Matrix) ProtoMessage() {}
+
+func (x *Matrix) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Matrix.ProtoReflect.Descriptor instead.
+func (*Matrix) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Matrix) GetRows() uint64 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *Matrix) GetCols() uint64 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *Matrix) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Queries carry the DBInfo.version their hint is for, and answers the
+// version of the database that answered. A server rejects queries for
+// another version with FAILED_PRECONDITION: the client must refetch the
+// params and hint.
+type AnswerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         *Matrix                `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnswerRequest) Reset() {
+	*x = AnswerRequest{}
+	mi := &file_pir_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnswerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnswerRequest) ProtoMessage() {}
+
+func (x *AnswerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnswerRequest.ProtoReflect.Descriptor instead.
+func (*AnswerRequest) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AnswerRequest) GetQuery() *Matrix {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *AnswerRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type AnswerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Answer        *Matrix                `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"`
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnswerResponse) Reset() {
+	*x = AnswerResponse{}
+	mi := &file_pir_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnswerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnswerResponse) ProtoMessage() {}
+
+func (x *AnswerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnswerResponse.ProtoReflect.Descriptor instead.
+func (*AnswerResponse) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AnswerResponse) GetAnswer() *Matrix {
+	if x != nil {
+		return x.Answer
+	}
+	return nil
+}
+
+func (x *AnswerResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type AnswerBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queries       []*Matrix              `protobuf:"bytes,1,rep,name=queries,proto3" json:"queries,omitempty"`
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnswerBatchRequest) Reset() {
+	*x = AnswerBatchRequest{}
+	mi := &file_pir_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnswerBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnswerBatchRequest) ProtoMessage() {}
+
+func (x *AnswerBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnswerBatchRequest.ProtoReflect.Descriptor instead.
+func (*AnswerBatchRequest) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AnswerBatchRequest) GetQueries() []*Matrix {
+	if x != nil {
+		return x.Queries
+	}
+	return nil
+}
+
+func (x *AnswerBatchRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type AnswerBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Answers       []*Matrix              `protobuf:"bytes,1,rep,name=answers,proto3" json:"answers,omitempty"`
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnswerBatchResponse) Reset() {
+	*x = AnswerBatchResponse{}
+	mi := &file_pir_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnswerBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnswerBatchResponse) ProtoMessage() {}
+
+func (x *AnswerBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pir_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnswerBatchResponse.ProtoReflect.Descriptor instead.
+func (*AnswerBatchResponse) Descriptor() ([]byte, []int) {
+	return file_pir_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AnswerBatchResponse) GetAnswers() []*Matrix {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+func (x *AnswerBatchResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+var File_pir_proto protoreflect.FileDescriptor
+
+const file_pir_proto_rawDesc = "" +
+	"\n" +
+	"\tpir.proto\x12\tsimplepir\"\x12\n" +
+	"\x10GetParamsRequest\"u\n" +
+	"\tLWEParams\x12\f\n" +
+	"\x01n\x18\x01 \x01(\x04R\x01n\x12\x14\n" +
+	"\x05sigma\x18\x02 \x01(\x01R\x05sigma\x12\f\n" +
+	"\x01m\x18\x03 \x01(\x04R\x01m\x12\x12\n" +
+	"\x04logq\x18\x04 \x01(\x04R\x04logq\x12\f\n" +
+	"\x01p\x18\x05 \x01(\x04R\x01p\x12\x14\n" +
+	"\x05delta\x18\x06 \x01(\x04R\x05delta\"\xd5\x01\n" +
+	"\x06DBInfo\x12\x10\n" +
+	"\x03num\x18\x01 \x01(\x04R\x03num\x12\x1d\n" +
+	"\n" +
+	"row_length\x18\x02 \x01(\x04R\trowLength\x12\x0e\n" +
+	"\x02ne\x18\x03 \x01(\x04R\x02ne\x12\f\n" +
+	"\x01x\x18\x04 \x01(\x04R\x01x\x12\f\n" +
+	"\x01l\x18\x05 \x01(\x04R\x01l\x12\f\n" +
+	"\x01m\x18\x06 \x01(\x04R\x01m\x12\x1c\n" +
+	"\tsquishing\x18\a \x01(\x04R\tsquishing\x12\x14\n" +
+	"\x05basis\x18\b \x01(\x04R\x05basis\x12\x12\n" +
+	"\x04cols\x18\t \x01(\x04R\x04cols\x12\x18\n" +
+	"\aversion\x18\n" +
+	" \x01(\x04R\aversion\"Q\n" +
+	"\rHintChunkInfo\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x04R\x05start\x12\x12\n" +
+	"\x04rows\x18\x02 \x01(\x04R\x04rows\x12\x16\n" +
+	"\x06digest\x18\x03 \x01(\fR\x06digest\"\xd7\x01\n" +
+	"\x06Params\x12%\n" +
+	"\x04info\x18\x01 \x01(\v2\x11.simplepir.DBInfoR\x04info\x12&\n" +
+	"\x03lwe\x18\x02 \x01(\v2\x14.simplepir.LWEParamsR\x03lwe\x12\x12\n" +
+	"\x04seed\x18\x03 \x01(\fR\x04seed\x12\x1b\n" +
+	"\thint_rows\x18\x04 \x01(\x04R\bhintRows\x12\x1b\n" +
+	"\thint_cols\x18\x05 \x01(\x04R\bhintCols\x120\n" +
+	"\x06chunks\x18\x06 \x03(\v2\x18.simplepir.HintChunkInfoR\x06chunks\"+\n" +
+	"\x11StreamHintRequest\x12\x16\n" +
+	"\x06chunks\x18\x01 \x03(\rR\x06chunks\"5\n" +
+	"\tHintChunk\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"D\n" +
+	"\x06Matrix\x12\x12\n" +
+	"\x04rows\x18\x01 \x01(\x04R\x04rows\x12\x12\n" +
+	"\x04cols\x18\x02 \x01(\x04R\x04cols\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"R\n" +
+	"\rAnswerRequest\x12'\n" +
+	"\x05query\x18\x01 \x01(\v2\x11.simplepir.MatrixR\x05query\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion\"U\n" +
+	"\x0eAnswerResponse\x12)\n" +
+	"\x06answer\x18\x01 \x01(\v2\x11.simplepir.MatrixR\x06answer\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion\"[\n" +
+	"\x12AnswerBatchRequest\x12+\n" +
+	"\aqueries\x18\x01 \x03(\v2\x11.simplepir.MatrixR\aqueries\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion\"\\\n" +
+	"\x13AnswerBatchResponse\x12+\n" +
+	"\aanswers\x18\x01 \x03(\v2\x11.simplepir.MatrixR\aanswers\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion2\x93\x02\n" +
+	"\x03PIR\x12;\n" +
+	"\tGetParams\x12\x1b.simplepir.GetParamsRequest\x1a\x11.simplepir.Params\x12B\n" +
+	"\n" +
+	"StreamHint\x12\x1c.simplepir.StreamHintRequest\x1a\x14.simplepir.HintChunk0\x01\x12=\n" +
+	"\x06Answer\x12\x18.simplepir.AnswerRequest\x1a\x19.simplepir.AnswerResponse\x12L\n" +
+	"\vAnswerBatch\x12\x1d.simplepir.AnswerBatchRequest\x1a\x1e.simplepir.AnswerBatchResponseB/Z-github.com/ryanleh/simplepir/pir/grpc;pirgrpcb\x06proto3"
+
+var (
+	file_pir_proto_rawDescOnce sync.Once
+	file_pir_proto_rawDescData []byte
+)
+
+func file_pir_proto_rawDescGZIP() []byte {
+	file_pir_proto_rawDescOnce.Do(func() {
+		file_pir_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pir_proto_rawDesc), len(file_pir_proto_rawDesc)))
+	})
+	return file_pir_proto_rawDescData
+}
+
+var file_pir_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_pir_proto_goTypes = []any{
+	(*GetParamsRequest)(nil),    // 0: simplepir.GetParamsRequest
+	(*LWEParams)(nil),           // 1: simplepir.LWEParams
+	(*DBInfo)(nil),              // 2: simplepir.DBInfo
+	(*HintChunkInfo)(nil),       // 3: simplepir.HintChunkInfo
+	(*Params)(nil),              // 4: simplepir.Params
+	(*StreamHintRequest)(nil),   // 5: simplepir.StreamHintRequest
+	(*HintChunk)(nil),           // 6: simplepir.HintChunk
+	(*Matrix)(nil),              // 7: simplepir.Matrix
+	(*AnswerRequest)(nil),       // 8: simplepir.AnswerRequest
+	(*AnswerResponse)(nil),      // 9: simplepir.AnswerResponse
+	(*AnswerBatchRequest)(nil),  // 10: simplepir.AnswerBatchRequest
+	(*AnswerBatchResponse)(nil), // 11: simplepir.AnswerBatchResponse
+}
+var file_pir_proto_depIdxs = []int32{
+	2,  // 0: simplepir.Params.info:type_name -> simplepir.DBInfo
+	1,  // 1: simplepir.Params.lwe:type_name -> simplepir.LWEParams
+	3,  // 2: simplepir.Params.chunks:type_name -> simplepir.HintChunkInfo
+	7,  // 3: simplepir.AnswerRequest.query:type_name -> simplepir.Matrix
+	7,  // 4: simplepir.AnswerResponse.answer:type_name -> simplepir.Matrix
+	7,  // 5: simplepir.AnswerBatchRequest.queries:type_name -> simplepir.Matrix
+	7,  // 6: simplepir.AnswerBatchResponse.answers:type_name -> simplepir.Matrix
+	0,  // 7: simplepir.PIR.GetParams:input_type -> simplepir.GetParamsRequest
+	5,  // 8: simplepir.PIR.StreamHint:input_type -> simplepir.StreamHintRequest
+	8,  // 9: simplepir.PIR.Answer:input_type -> simplepir.AnswerRequest
+	10, // 10: simplepir.PIR.AnswerBatch:input_type -> simplepir.AnswerBatchRequest
+	4,  // 11: simplepir.PIR.GetParams:output_type -> simplepir.Params
+	6,  // 12: simplepir.PIR.StreamHint:output_type -> simplepir.HintChunk
+	9,  // 13: simplepir.PIR.Answer:output_type -> simplepir.AnswerResponse
+	11, // 14: simplepir.PIR.AnswerBatch:output_type -> simplepir.AnswerBatchResponse
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_pir_proto_init() }
+func file_pir_proto_init() {
+	if File_pir_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pir_proto_rawDesc), len(file_pir_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pir_proto_goTypes,
+		DependencyIndexes: file_pir_proto_depIdxs,
+		MessageInfos:      file_pir_proto_msgTypes,
+	}.Build()
+	File_pir_proto = out.File
+	file_pir_proto_goTypes = nil
+	file_pir_proto_depIdxs = nil
+}
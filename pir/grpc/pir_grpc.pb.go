@@ -0,0 +1,249 @@
+// gRPC contract for SimplePIR. Matrices are sent as the little-endian
+// encoding of their elements in row-major order, with 4-byte elements if
+// logq is 32 and 8-byte elements if logq is 64.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: pir.proto
+
+package pirgrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PIR_GetParams_FullMethodName   = "/simplepir.PIR/GetParams"
+	PIR_StreamHint_FullMethodName  = "/simplepir.PIR/StreamHint"
+	PIR_Answer_FullMethodName      = "/simplepir.PIR/Answer"
+	PIR_AnswerBatch_FullMethodName = "/simplepir.PIR/AnswerBatch"
+)
+
+// PIRClient is the client API for PIR service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PIRClient interface {
+	// Everything a client needs before downloading the hint.
+	GetParams(ctx context.Context, in *GetParamsRequest, opts ...grpc.CallOption) (*Params, error)
+	// Streams the hint in blocks of rows, as listed in Params.chunks.
+	StreamHint(ctx context.Context, in *StreamHintRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HintChunk], error)
+	Answer(ctx context.Context, in *AnswerRequest, opts ...grpc.CallOption) (*AnswerResponse, error)
+	// Answers several queries against the same database in one call.
+	AnswerBatch(ctx context.Context, in *AnswerBatchRequest, opts ...grpc.CallOption) (*AnswerBatchResponse, error)
+}
+
+type pIRClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPIRClient(cc grpc.ClientConnInterface) PIRClient {
+	return &pIRClient{cc}
+}
+
+func (c *pIRClient) GetParams(ctx context.Context, in *GetParamsRequest, opts ...grpc.CallOption) (*Params, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Params)
+	err := c.cc.Invoke(ctx, PIR_GetParams_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pIRClient) StreamHint(ctx context.Context, in *StreamHintRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HintChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PIR_ServiceDesc.Streams[0], PIR_StreamHint_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamHintRequest, HintChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PIR_StreamHintClient = grpc.ServerStreamingClient[HintChunk]
+
+func (c *pIRClient) Answer(ctx context.Context, in *AnswerRequest, opts ...grpc.CallOption) (*AnswerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnswerResponse)
+	err := c.cc.Invoke(ctx, PIR_Answer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pIRClient) AnswerBatch(ctx context.Context, in *AnswerBatchRequest, opts ...grpc.CallOption) (*AnswerBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnswerBatchResponse)
+	err := c.cc.Invoke(ctx, PIR_AnswerBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PIRServer is the server API for PIR service.
+// All implementations must embed UnimplementedPIRServer
+// for forward compatibility.
+type PIRServer interface {
+	// Everything a client needs before downloading the hint.
+	GetParams(context.Context, *GetParamsRequest) (*Params, error)
+	// Streams the hint in blocks of rows, as listed in Params.chunks.
+	StreamHint(*StreamHintRequest, grpc.ServerStreamingServer[HintChunk]) error
+	Answer(context.Context, *AnswerRequest) (*AnswerResponse, error)
+	// Answers several queries against the same database in one call.
+	AnswerBatch(context.Context, *AnswerBatchRequest) (*AnswerBatchResponse, error)
+	mustEmbedUnimplementedPIRServer()
+}
+
+// UnimplementedPIRServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPIRServer struct{}
+
+func (UnimplementedPIRServer) GetParams(context.Context, *GetParamsRequest) (*Params, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetParams not implemented")
+}
+func (UnimplementedPIRServer) StreamHint(*StreamHintRequest, grpc.ServerStreamingServer[HintChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamHint not implemented")
+}
+func (UnimplementedPIRServer) Answer(context.Context, *AnswerRequest) (*AnswerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Answer not implemented")
+}
+func (UnimplementedPIRServer) AnswerBatch(context.Context, *AnswerBatchRequest) (*AnswerBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnswerBatch not implemented")
+}
+func (UnimplementedPIRServer) mustEmbedUnimplementedPIRServer() {}
+func (UnimplementedPIRServer) testEmbeddedByValue()             {}
+
+// UnsafePIRServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PIRServer will
+// result in compilation errors.
+type UnsafePIRServer interface {
+	mustEmbedUnimplementedPIRServer()
+}
+
+func RegisterPIRServer(s grpc.ServiceRegistrar, srv PIRServer) {
+	// If the following call pancis, it indicates UnimplementedPIRServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PIR_ServiceDesc, srv)
+}
+
+func _PIR_GetParams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetParamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PIRServer).GetParams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PIR_GetParams_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PIRServer).GetParams(ctx, req.(*GetParamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PIR_StreamHint_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamHintRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PIRServer).StreamHint(m, &grpc.GenericServerStream[StreamHintRequest, HintChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PIR_StreamHintServer = grpc.ServerStreamingServer[HintChunk]
+
+func _PIR_Answer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnswerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PIRServer).Answer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PIR_Answer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PIRServer).Answer(ctx, req.(*AnswerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PIR_AnswerBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnswerBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PIRServer).AnswerBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PIR_AnswerBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PIRServer).AnswerBatch(ctx, req.(*AnswerBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PIR_ServiceDesc is the grpc.ServiceDesc for PIR service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PIR_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "simplepir.PIR",
+	HandlerType: (*PIRServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetParams",
+			Handler:    _PIR_GetParams_Handler,
+		},
+		{
+			MethodName: "Answer",
+			Handler:    _PIR_Answer_Handler,
+		},
+		{
+			MethodName: "AnswerBatch",
+			Handler:    _PIR_AnswerBatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamHint",
+			Handler:       _PIR_StreamHint_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pir.proto",
+}
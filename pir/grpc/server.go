@@ -0,0 +1,122 @@
+// Package pirgrpc serves SimplePIR over gRPC (see pir.proto for the
+// contract), and provides a client for it built on pir.Client.
+package pirgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pir.proto
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+)
+
+// Default size of the hint chunks, well below gRPC's default 4 MiB limit on
+// received messages.
+const DefaultChunkBytes = 1 << 20
+
+type Service[T matrix.Elem] struct {
+	UnimplementedPIRServer
+
+	server *pir.Server[T]
+	params *Params
+	chunks []pir.HintChunkInfo
+}
+
+// The server must have its hint and the seed of A, so e.g. not be decoded
+// from gob.
+func NewService[T matrix.Elem](server *pir.Server[T], chunkBytes uint64) *Service[T] {
+	if server.MatrixA() == nil {
+		panic("Server has no seed for A")
+	}
+
+	m := server.HintManifest(chunkBytes)
+	return &Service[T]{
+		server: server,
+		params: encodeManifest(m),
+		chunks: m.Chunks,
+	}
+}
+
+func Register[T matrix.Elem](s *grpc.Server, server *pir.Server[T]) *Service[T] {
+	service := NewService(server, DefaultChunkBytes)
+	RegisterPIRServer(s, service)
+	return service
+}
+
+func (s *Service[T]) GetParams(ctx context.Context, req *GetParamsRequest) (*Params, error) {
+	return s.params, nil
+}
+
+func (s *Service[T]) StreamHint(req *StreamHintRequest, stream grpc.ServerStreamingServer[HintChunk]) error {
+	indices := req.Chunks
+	if len(indices) == 0 {
+		indices = make([]uint32, len(s.chunks))
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	for _, i := range indices {
+		if int(i) >= len(s.chunks) {
+			return status.Errorf(codes.InvalidArgument, "chunk %d out of range", i)
+		}
+		chunk := &HintChunk{Index: i, Data: s.server.HintChunk(s.chunks[i])}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service[T]) Answer(ctx context.Context, req *AnswerRequest) (*AnswerResponse, error) {
+	if err := s.checkVersion(req.Version); err != nil {
+		return nil, err
+	}
+	query, err := s.decodeQuery(req.Query)
+	if err != nil {
+		return nil, err
+	}
+	ans := s.server.Answer(query)
+	return &AnswerResponse{Answer: encodeMatrix(ans.Answer), Version: query.Version}, nil
+}
+
+func (s *Service[T]) AnswerBatch(ctx context.Context, req *AnswerBatchRequest) (*AnswerBatchResponse, error) {
+	if err := s.checkVersion(req.Version); err != nil {
+		return nil, err
+	}
+	queries := make([]*pir.Query[T], len(req.Queries))
+	for i, q := range req.Queries {
+		query, err := s.decodeQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = query
+	}
+
+	res := &AnswerBatchResponse{Version: s.server.DBInfo().Version}
+	for _, ans := range s.server.AnswerMulti(queries) {
+		res.Answers = append(res.Answers, encodeMatrix(ans.Answer))
+	}
+	return res, nil
+}
+
+func (s *Service[T]) decodeQuery(m *Matrix) (*pir.Query[T], error) {
+	q, err := decodeMatrix[T](m, s.server.QueryRows(), 1)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "bad query: %v", err)
+	}
+	return &pir.Query[T]{Query: q, Version: s.server.DBInfo().Version}, nil
+}
+
+// Answers computed against another version's hint would decode to garbage.
+func (s *Service[T]) checkVersion(version uint64) error {
+	if v := s.server.DBInfo().Version; version != v {
+		return status.Errorf(codes.FailedPrecondition, "query for database version %d, serving %d", version, v)
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package pir
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/ryanleh/simplepir/lwe"
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+// A block of consecutive hint rows, encoded as little-endian elements.
+type HintChunkInfo struct {
+	Start  uint64
+	Rows   uint64
+	Digest [sha256.Size]byte
+}
+
+// Everything a client needs to download the hint in chunks, and to verify
+// them, before it can query.
+type HintManifest struct {
+	Info   *DBInfo
+	Params *lwe.Params
+	Seed   rand.PRGKey // of the A matrix
+
+	Rows   uint64
+	Cols   uint64
+	Chunks []HintChunkInfo
+}
+
+// Splits the hint into chunks of about 'chunkBytes' bytes each.
+func (s *Server[T]) HintManifest(chunkBytes uint64) *HintManifest {
+	if s.hint == nil {
+		panic("Hint was dropped")
+	}
+
+	rowBytes := s.hint.Cols() * (T(0).Bitlen() / 8)
+	chunkRows := chunkBytes / rowBytes
+	if chunkRows == 0 {
+		chunkRows = 1
+	}
+
+	m := &HintManifest{
+		Info:   s.db.Info,
+		Params: s.params,
+		Seed:   *s.matrixAseed,
+		Rows:   s.hint.Rows(),
+		Cols:   s.hint.Cols(),
+	}
+
+	for start := uint64(0); start < m.Rows; start += chunkRows {
+		c := HintChunkInfo{Start: start, Rows: chunkRows}
+		if c.Rows > m.Rows-start {
+			c.Rows = m.Rows - start
+		}
+		c.Digest = sha256.Sum256(s.HintChunk(c))
+		m.Chunks = append(m.Chunks, c)
+	}
+
+	return m
+}
+
+func (s *Server[T]) HintChunk(c HintChunkInfo) []byte {
+	if s.hint == nil {
+		panic("Hint was dropped")
+	}
+	if c.Start+c.Rows > s.hint.Rows() {
+		panic("Chunk out of range")
+	}
+
+	cols := s.hint.Cols()
+	rows := s.hint.Data()[c.Start*cols : (c.Start+c.Rows)*cols]
+
+	elemSz := T(0).Bitlen() / 8
+	buf := make([]byte, uint64(len(rows))*elemSz)
+	for i, v := range rows {
+		if elemSz == 4 {
+			binary.LittleEndian.PutUint32(buf[uint64(i)*elemSz:], uint32(v))
+		} else {
+			binary.LittleEndian.PutUint64(buf[uint64(i)*elemSz:], uint64(v))
+		}
+	}
+	return buf
+}
+
+// Assembles the hint from chunks received in any order, verifying each
+// against the manifest. Chunks are decoded in place, so the hint is never
+// copied.
+type HintBuilder[T matrix.Elem] struct {
+	manifest *HintManifest
+	hint     *matrix.Matrix[T]
+	done     []bool
+}
+
+// Returns an error if the manifest, e.g. received from the network, does
+// not describe a hint of the expected shape split into contiguous chunks.
+func NewHintBuilder[T matrix.Elem](m *HintManifest) (*HintBuilder[T], error) {
+	if err := m.Validate(T(0).Bitlen()); err != nil {
+		return nil, err
+	}
+
+	return &HintBuilder[T]{
+		manifest: m,
+		hint:     matrix.Zeros[T](m.Rows, m.Cols),
+		done:     make([]bool, len(m.Chunks)),
+	}, nil
+}
+
+// Checks that the manifest matches its DBInfo and 'logq'-bit elements, that
+// the hint can be allocated, and that its chunks are in bounds, in order,
+// and cover every row once.
+func (m *HintManifest) Validate(logq uint64) error {
+	if m.Info == nil || m.Params == nil || m.Info.Params == nil {
+		return fmt.Errorf("manifest has no DBInfo or params")
+	}
+	if *m.Params != *m.Info.Params {
+		return fmt.Errorf("manifest params do not match DBInfo")
+	}
+	if m.Params.Logq != logq {
+		return fmt.Errorf("manifest is for %d-bit elements, expected %d", m.Params.Logq, logq)
+	}
+	if m.Rows != m.Info.L || m.Cols != m.Params.N {
+		return fmt.Errorf("manifest does not match DBInfo")
+	}
+
+	// Each column holds ceil(Num/M) records of Ne rows each
+	info := m.Info
+	if info.Num == 0 || info.Ne == 0 || info.M == 0 {
+		return fmt.Errorf("empty DBInfo")
+	}
+	perCol := info.Num / info.M
+	if info.Num%info.M != 0 {
+		perCol++
+	}
+	if hi, l := bits.Mul64(info.Ne, perCol); hi != 0 || l != info.L {
+		return fmt.Errorf("database height %d does not match %d records of %d elements",
+			info.L, info.Num, info.Ne)
+	}
+	if !validSquishing(info, logq) {
+		return fmt.Errorf("no layout packs %d values of %d bits per element", info.Squishing, info.Basis)
+	}
+	if hi, n := bits.Mul64(m.Rows, m.Cols); hi != 0 || n > math.MaxInt/(logq/8) {
+		return fmt.Errorf("%d-by-%d hint is too large", m.Rows, m.Cols)
+	}
+
+	next := uint64(0)
+	for i, c := range m.Chunks {
+		if c.Start != next || c.Rows == 0 || c.Rows > m.Rows-c.Start {
+			return fmt.Errorf("chunk %d holds rows [%d, %d+%d), expected rows from %d",
+				i, c.Start, c.Start, c.Rows, next)
+		}
+		next = c.Start + c.Rows
+	}
+	if next != m.Rows {
+		return fmt.Errorf("chunks cover %d of %d rows", next, m.Rows)
+	}
+
+	return nil
+}
+
+// Squishing is 1, or the ratio of a layout with a kernel, which bounds how
+// far queries are padded.
+func validSquishing(info *DBInfo, logq uint64) bool {
+	if info.Squishing == 1 {
+		return true
+	}
+
+	layouts := matrix.SquishLayouts[matrix.Elem32]()
+	if logq == 64 {
+		layouts = matrix.SquishLayouts[matrix.Elem64]()
+	}
+	for _, l := range layouts {
+		if l.Ratio == info.Squishing && (info.Basis == 0 || l.Basis == info.Basis) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *HintBuilder[T]) AddChunk(i int, data []byte) error {
+	if i < 0 || i >= len(b.manifest.Chunks) {
+		return fmt.Errorf("chunk %d out of range", i)
+	}
+
+	c := b.manifest.Chunks[i]
+	if sha256.Sum256(data) != c.Digest {
+		return fmt.Errorf("chunk %d does not match its digest", i)
+	}
+
+	// In bounds, as the manifest was validated
+	rows := b.hint.Data()[c.Start*b.manifest.Cols : (c.Start+c.Rows)*b.manifest.Cols]
+	if err := decodeChunk(rows, data); err != nil {
+		return fmt.Errorf("chunk %d: %w", i, err)
+	}
+
+	b.done[i] = true
+	return nil
+}
+
+func decodeChunk[T matrix.Elem](rows []T, data []byte) error {
+	elemSz := T(0).Bitlen() / 8
+	if uint64(len(data)) != uint64(len(rows))*elemSz {
+		return fmt.Errorf("got %d bytes, expected %d", len(data), uint64(len(rows))*elemSz)
+	}
+
+	for j := range rows {
+		if elemSz == 4 {
+			rows[j] = T(binary.LittleEndian.Uint32(data[uint64(j)*elemSz:]))
+		} else {
+			rows[j] = T(binary.LittleEndian.Uint64(data[uint64(j)*elemSz:]))
+		}
+	}
+	return nil
+}
+
+// Chunks still to be downloaded, e.g. to resume after a failure.
+func (b *HintBuilder[T]) Missing() []int {
+	var out []int
+	for i, done := range b.done {
+		if !done {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (b *HintBuilder[T]) Done() bool {
+	return len(b.Missing()) == 0
+}
+
+// Returns a client owning the assembled hint, once every chunk, and so
+// every row, was received.
+func (b *HintBuilder[T]) Client() (*Client[T], error) {
+	if missing := b.Missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("hint is incomplete: missing chunks %v", missing)
+	}
+
+	c := NewClient[T](nil, &b.manifest.Seed, b.manifest.Info)
+	c.hint = b.hint
+	return c, nil
+}
+
+// Recovers without a stored hint, for clients that cannot keep one: the
+// hint is streamed again and only the rows of H * s needed for this record
+// are computed. Every chunk is fetched, so that the server does not learn
+// which row is being recovered.
+func (c *Client[T]) RecoverStateless(s *Secret[T], ansIn *Answer[T], m *HintManifest,
+	fetch func(HintChunkInfo) ([]byte, error)) (uint64, error) {
+	if err := c.checkRecover(s, ansIn); err != nil {
+		return 0, err
+	}
+
+	// Rows not covered by any chunk would silently be left masked
+	if err := m.Validate(T(0).Bitlen()); err != nil {
+		return 0, err
+	}
+	if m.Rows != c.dbinfo.L || m.Cols != c.params.N {
+		return 0, fmt.Errorf("manifest does not match DBInfo")
+	}
+
+	first := (s.index / c.dbinfo.M) * c.dbinfo.Ne
+	last := first + c.dbinfo.Ne
+	ans := ansIn.Answer.Copy()
+
+	for i, chunk := range m.Chunks {
+		data, err := fetch(chunk)
+		if err != nil {
+			return 0, err
+		}
+		if sha256.Sum256(data) != chunk.Digest {
+			return 0, fmt.Errorf("chunk %d does not match its digest", i)
+		}
+
+		lo, hi := chunk.Start, chunk.Start+chunk.Rows
+		if hi <= first || lo >= last {
+			continue
+		}
+
+		rows := make([]T, chunk.Rows*m.Cols)
+		if err := decodeChunk(rows, data); err != nil {
+			return 0, fmt.Errorf("chunk %d: %w", i, err)
+		}
+		hint := matrix.NewFromRaw(rows, chunk.Rows, m.Cols)
+
+		if lo < first {
+			lo = first
+		}
+		if hi > last {
+			hi = last
+		}
+		for r := lo; r < hi; r++ {
+			hs := matrix.Mul(hint.RowsDeepCopy(r-chunk.Start, 1), s.secret)
+			ans.Set(r, 0, ans.Get(r, 0)-hs.Get(0, 0))
+		}
+	}
+
+	s.state = SecretRecovered
+	return c.Decode(ans, s.index), nil
+}
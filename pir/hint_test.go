@@ -0,0 +1,191 @@
+package pir
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+func testHintChunks[T matrix.Elem](t *testing.T, N uint64, d uint64, chunks uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+	server := NewServerSeed(db, testKey(prg))
+	chunkBytes := server.Hint().Size() * (T(0).Bitlen() / 8) / chunks
+
+	// The manifest travels separately from the chunks
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(server.HintManifest(chunkBytes)); err != nil {
+		t.Fatal(err)
+	}
+	manifest := new(HintManifest)
+	if err := gob.NewDecoder(&buf).Decode(manifest); err != nil {
+		t.Fatal(err)
+	}
+	if uint64(len(manifest.Chunks)) < chunks {
+		t.Fatalf("Expected %d chunks, got %d", chunks, len(manifest.Chunks))
+	}
+
+	builder, err := NewHintBuilder[T](manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Interrupted download: fetch every other chunk, backwards
+	for i := len(manifest.Chunks) - 1; i >= 0; i -= 2 {
+		if err := builder.AddChunk(i, server.HintChunk(manifest.Chunks[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	missing := builder.Missing()
+	if len(missing) != len(manifest.Chunks)/2 || builder.Done() {
+		t.Fatalf("%d chunks missing out of %d", len(missing), len(manifest.Chunks))
+	}
+	if _, err := builder.Client(); err == nil {
+		t.Fatal("Built a client from an incomplete hint")
+	}
+
+	corrupt := server.HintChunk(manifest.Chunks[missing[0]])
+	corrupt[0] ^= 1
+	if err := builder.AddChunk(missing[0], corrupt); err == nil {
+		t.Fatal("Accepted corrupted chunk")
+	}
+
+	// Resume
+	for _, i := range builder.Missing() {
+		if err := builder.AddChunk(i, server.HintChunk(manifest.Chunks[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client, err := builder.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.Hint().Equals(server.Hint()) {
+		t.Fatal("Assembled hint does not match")
+	}
+
+	runPIR(t, client, server, db, N/2)
+}
+
+// Malformed manifests are rejected before any chunk is decoded.
+func testBadManifest[T matrix.Elem](t *testing.T, N uint64, d uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+	server := NewServerSeed(db, testKey(prg))
+	chunkBytes := server.Hint().Size() * (T(0).Bitlen() / 8) / 4
+
+	for name, corrupt := range map[string]func(m *HintManifest){
+		"out of bounds": func(m *HintManifest) { m.Chunks[len(m.Chunks)-1].Rows += 1 },
+		"overflow":      func(m *HintManifest) { m.Chunks[1].Rows = -m.Chunks[1].Start },
+		"overlap":       func(m *HintManifest) { m.Chunks[1].Start -= 1 },
+		"gap":           func(m *HintManifest) { m.Chunks = append(m.Chunks[:1], m.Chunks[2:]...) },
+		"uncovered":     func(m *HintManifest) { m.Chunks = m.Chunks[:len(m.Chunks)-1] },
+		"empty chunk":   func(m *HintManifest) { m.Chunks = append(m.Chunks, HintChunkInfo{Start: m.Rows}) },
+		"no chunks":     func(m *HintManifest) { m.Chunks = nil },
+		"shape":         func(m *HintManifest) { m.Rows += 1 },
+		"params": func(m *HintManifest) {
+			p := *m.Params
+			p.P += 1
+			m.Params = &p
+		},
+	} {
+		m := server.HintManifest(chunkBytes)
+		corrupt(m)
+		if _, err := NewHintBuilder[T](m); err == nil {
+			t.Fatalf("Accepted manifest with %s", name)
+		}
+	}
+
+	// Params are compared by value
+	m := server.HintManifest(chunkBytes)
+	p := *m.Params
+	m.Params = &p
+	if _, err := NewHintBuilder[T](m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBadManifest32(t *testing.T) {
+	testBadManifest[matrix.Elem32](t, uint64(1<<16), uint64(8))
+}
+
+func TestBadManifest64(t *testing.T) {
+	testBadManifest[matrix.Elem64](t, uint64(1<<14), uint64(32))
+}
+
+func TestHintChunks32(t *testing.T) {
+	testHintChunks[matrix.Elem32](t, uint64(1<<16), uint64(8), 5)
+}
+
+func TestHintChunks64(t *testing.T) {
+	testHintChunks[matrix.Elem64](t, uint64(1<<14), uint64(32), 4)
+}
+
+func testStateless[T matrix.Elem](t *testing.T, N uint64, d uint64, index uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+	server := NewServerSeed(db, testKey(prg))
+	manifest := server.HintManifest(server.Hint().Size() * (T(0).Bitlen() / 8) / 4)
+
+	// Keeps no hint at all
+	client := NewClientWithKey[T](nil, &manifest.Seed, manifest.Info, testKey(prg))
+
+	fetched := 0
+	fetch := func(c HintChunkInfo) ([]byte, error) {
+		fetched += 1
+		return server.HintChunk(c), nil
+	}
+
+	secret, query := client.Query(index)
+	answer := server.Answer(query)
+	val, err := client.RecoverStateless(secret, answer, manifest, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != db.GetElem(index) {
+		t.Fatalf("Querying index %d: Got %d instead of %d", index, val, db.GetElem(index))
+	}
+	if fetched != len(manifest.Chunks) {
+		t.Fatalf("Fetched %d of %d chunks", fetched, len(manifest.Chunks))
+	}
+
+	corrupt := func(c HintChunkInfo) ([]byte, error) {
+		data := server.HintChunk(c)
+		data[len(data)-1] ^= 1
+		return data, nil
+	}
+	if _, err := client.RecoverStateless(secret, answer, manifest, corrupt); err == nil {
+		t.Fatal("Accepted corrupted chunk")
+	}
+
+	// Chunks that leave rows uncovered would leave them masked
+	partial := *manifest
+	partial.Chunks = manifest.Chunks[:len(manifest.Chunks)-1]
+	if _, err := client.RecoverStateless(secret, answer, &partial, fetch); err == nil {
+		t.Fatal("Accepted manifest that does not cover the hint")
+	}
+
+	// Out of range indices are rejected before fetching anything
+	fetched = 0
+	for _, i := range []uint64{N, 1 << 62} {
+		secret, query := client.Query(i)
+		if _, err := client.RecoverStateless(secret, server.Answer(query), manifest, fetch); err == nil {
+			t.Fatalf("Recovered index %d out of range", i)
+		}
+	}
+	if fetched != 0 {
+		t.Fatalf("Fetched %d chunks for out of range indices", fetched)
+	}
+}
+
+func TestStateless32(t *testing.T) {
+	testStateless[matrix.Elem32](t, uint64(1<<16), uint64(8), 40000)
+}
+
+func TestStateless64(t *testing.T) {
+	testStateless[matrix.Elem64](t, uint64(1<<14), uint64(32), 10000)
+}
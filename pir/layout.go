@@ -0,0 +1,93 @@
+package pir
+
+import (
+	"github.com/ryanleh/simplepir/lwe"
+)
+
+type LayoutTarget int
+
+const (
+	MinimizeHint   LayoutTarget = iota // smallest offline download
+	MinimizeOnline                     // smallest query + answer
+	HintBudget                         // smallest query + answer, with a bounded hint
+)
+
+// A candidate database shape, along with its predicted costs.
+type Layout struct {
+	Info *DBInfo
+	Costs
+}
+
+func newLayout(info *DBInfo) *Layout {
+	return &Layout{
+		Info:  info,
+		Costs: *info.Costs(),
+	}
+}
+
+func (l *Layout) Params() *lwe.Params {
+	return l.Info.Params
+}
+
+// Enumerates candidate layouts, fixing the database width M to each power
+// of two supported by the LWE parameter tables and using the largest
+// plaintext modulus allowed for that width.
+func PlanLayouts(logq, num, rowLength uint64) []*Layout {
+	if (num == 0) || (rowLength == 0) {
+		panic("Empty database!")
+	}
+
+	var out []*Layout
+	for m := uint64(1); m <= lwe.MaxSamples(logq); m *= 2 {
+		params := lwe.NewParams(logq, m)
+		if params == nil {
+			panic("Could not find LWE Params")
+		}
+
+		params = lwe.NewParamsFixedP(logq, m, params.P)
+		out = append(out, newLayout(NewDBInfoFixedParams(num, rowLength, params, true)))
+
+		// Any wider database is only padding
+		dbElems, _ := numEntries(num, rowLength, params.P)
+		if m >= dbElems {
+			break
+		}
+	}
+
+	return out
+}
+
+// Picks the best candidate layout for the given target. The hint budget
+// (in bytes) is only used with HintBudget; returns nil if no layout fits.
+//
+// To build the database, pass the layout's Params() to
+// NewDatabaseFixedParams.
+func PlanLayout(logq, num, rowLength uint64, target LayoutTarget, hintBudget uint64) *Layout {
+	var best *Layout
+	for _, l := range PlanLayouts(logq, num, rowLength) {
+		if target == HintBudget && l.HintBytes > hintBudget {
+			continue
+		}
+		if best == nil || l.betterThan(best, target) {
+			best = l
+		}
+	}
+
+	return best
+}
+
+func (l *Layout) betterThan(o *Layout, target LayoutTarget) bool {
+	a := []uint64{l.OnlineBytes(), l.HintBytes, l.ServerOps}
+	b := []uint64{o.OnlineBytes(), o.HintBytes, o.ServerOps}
+	if target == MinimizeHint {
+		a[0], a[1] = a[1], a[0]
+		b[0], b[1] = b[1], b[0]
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
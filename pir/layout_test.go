@@ -0,0 +1,85 @@
+package pir
+
+import (
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func testLayout[T matrix.Elem](t *testing.T, N uint64, d uint64, target LayoutTarget, index uint64) {
+	logq := T(0).Bitlen()
+	layouts := PlanLayouts(logq, N, d)
+	if len(layouts) == 0 {
+		t.Fatal("No layouts found")
+	}
+
+	// Pick a budget that rules out the smallest-hint layout
+	budget := uint64(0)
+	for _, l := range layouts {
+		if l.HintBytes > budget {
+			budget = l.HintBytes
+		}
+	}
+	budget /= 2
+
+	best := PlanLayout(logq, N, d, target, budget)
+	if best == nil {
+		t.Fatal("No layout fits the budget")
+	}
+
+	for _, l := range layouts {
+		if l.Info.L*l.Info.M < N*l.Info.Ne {
+			t.Fatalf("Layout %d-by-%d too small", l.Info.L, l.Info.M)
+		}
+
+		switch target {
+		case MinimizeHint:
+			if l.HintBytes < best.HintBytes {
+				t.Fatalf("Found smaller hint: %d < %d", l.HintBytes, best.HintBytes)
+			}
+		case MinimizeOnline:
+			if l.OnlineBytes() < best.OnlineBytes() {
+				t.Fatalf("Found smaller online comm: %d < %d", l.OnlineBytes(), best.OnlineBytes())
+			}
+		case HintBudget:
+			if best.HintBytes > budget {
+				t.Fatalf("Hint over budget: %d > %d", best.HintBytes, budget)
+			}
+			if l.HintBytes <= budget && l.OnlineBytes() < best.OnlineBytes() {
+				t.Fatalf("Found smaller online comm: %d < %d", l.OnlineBytes(), best.OnlineBytes())
+			}
+		}
+	}
+
+	prg := rand.NewRandomBufPRG()
+	db := NewDatabaseRandomFixedParams[T](prg, N, d, best.Params())
+	if db.Info.L != best.Info.L || db.Info.M != best.Info.M {
+		t.Fatal("Layout mismatch")
+	}
+
+	server := NewServer(db)
+	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+
+	if server.Hint().Size()*(logq/8) != best.HintBytes {
+		t.Fatalf("Predicted hint of %d bytes, got %d", best.HintBytes, server.Hint().Size()*(logq/8))
+	}
+
+	runPIR(t, client, server, db, index)
+}
+
+func TestLayoutMinHint32(t *testing.T) {
+	testLayout[matrix.Elem32](t, uint64(1<<16), uint64(8), MinimizeHint, 1000)
+}
+
+func TestLayoutMinOnline32(t *testing.T) {
+	testLayout[matrix.Elem32](t, uint64(1<<16), uint64(8), MinimizeOnline, 1000)
+}
+
+func TestLayoutBudget32(t *testing.T) {
+	testLayout[matrix.Elem32](t, uint64(1<<16), uint64(8), HintBudget, 1000)
+}
+
+func TestLayoutMinOnline64(t *testing.T) {
+	testLayout[matrix.Elem64](t, uint64(1<<14), uint64(32), MinimizeOnline, 5)
+}
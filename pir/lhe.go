@@ -9,12 +9,17 @@ type SecretLHE[T matrix.Elem] struct {
 	secret *matrix.Matrix[T]
 	interm *matrix.Matrix[T]
 	arr    *matrix.Matrix[T]
+	state  SecretState
 }
 
 func (s *SecretLHE[T]) Secret() *matrix.Matrix[T] {
 	return s.secret
 }
 
+func (s *SecretLHE[T]) State() SecretState {
+	return s.state
+}
+
 func (c *Client[T]) PreprocessQueryLHE() *SecretLHE[T] {
 	inSecret := c.GenerateSecret()
 	return c.PreprocessQueryLHEGivenSecret(inSecret)
@@ -40,7 +45,9 @@ func (c *Client[T]) PreprocessQueryLHEGivenSecret(inSecret *matrix.Matrix[T]) *S
 	}
 }
 
+// Panics with ErrSecretReused if 's' was already used.
 func (c *Client[T]) QueryLHEPreprocessed(arrIn *matrix.Matrix[T], s *SecretLHE[T]) *Query[T] {
+	s.state = s.state.markQueried()
 	arr := arrIn.Copy()
 
 	if arr.Rows() != c.dbinfo.M || arr.Cols() != 1 {
@@ -52,7 +59,7 @@ func (c *Client[T]) QueryLHEPreprocessed(arrIn *matrix.Matrix[T], s *SecretLHE[T
 	arr.AppendZeros(s.query.Rows() - arrIn.Rows())
 	s.query.Add(arr)
 
-	return &Query[T]{s.query}
+	return &Query[T]{Query: s.query, Version: c.dbinfo.Version}
 }
 
 func (c *Client[T]) QueryLHE(arrIn *matrix.Matrix[T]) (*SecretLHE[T], *Query[T]) {
@@ -78,8 +85,12 @@ func (c *Client[T]) RecoverManyLHE(s *SecretLHE[T], ansIn *Answer[T]) *matrix.Ma
 	if c.dbinfo.Ne != 1 {
 		panic("Not yet supported")
 	}
+	s.state = s.state.markRecovered()
 
 	if s.interm == nil {
+		if c.hint == nil {
+			panic("Client has no hint")
+		}
 		s.interm = matrix.Mul(c.hint, s.secret)
 	}
 
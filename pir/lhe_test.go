@@ -3,6 +3,7 @@ package pir
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ryanleh/simplepir/lwe"
 	"github.com/ryanleh/simplepir/matrix"
@@ -86,3 +87,35 @@ func TestLHEBigDB32(t *testing.T) {
 func TestLHEBigDB64(t *testing.T) {
 	testLHE[matrix.Elem64](t, uint64(1<<14), uint64(9))
 }
+
+func benchmarkLHE[T matrix.Elem](b *testing.B) {
+	N, d := benchParams()
+	if d > 9 {
+		d = 9 // LHE requires 2^d <= p
+	}
+
+	prg := rand.NewRandomBufPRG()
+	info := NewDBInfo(T(0).Bitlen(), N, d)
+	params := lwe.NewParamsFixedP(T(0).Bitlen(), info.M, 512)
+	db := NewDatabaseRandomFixedParams[T](prg, N, d, params)
+	arr := matrix.Rand[T](prg, db.Info.M, 1, params.P)
+
+	server := NewServer(db)
+	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		secret, query := client.QueryLHE(arr)
+		client.RecoverManyLHE(secret, server.Answer(query))
+	}
+	reportRate(b, db.Info, start, 1)
+}
+
+func BenchmarkLHE32(b *testing.B) {
+	benchmarkLHE[matrix.Elem32](b)
+}
+
+func BenchmarkLHE64(b *testing.B) {
+	benchmarkLHE[matrix.Elem64](b)
+}
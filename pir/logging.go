@@ -11,8 +11,14 @@ func printTime(start time.Time) time.Duration {
 }
 
 func printRate(info *DBInfo, elapsed time.Duration, batch_sz int) float64 {
-	rate := math.Log2(float64((info.P()))) * float64(info.L*info.M) * float64(batch_sz) /
-		float64(8*1024*1024*elapsed.Seconds())
+	rate := throughput(info, elapsed, batch_sz)
 	fmt.Printf("\tRate: %f MB/s\n", rate)
 	return rate
 }
+
+// MB of database processed per second, answering 'batch_sz' queries in
+// 'elapsed'.
+func throughput(info *DBInfo, elapsed time.Duration, batch_sz int) float64 {
+	return math.Log2(float64((info.P()))) * float64(info.L*info.M) * float64(batch_sz) /
+		float64(8*1024*1024*elapsed.Seconds())
+}
@@ -0,0 +1,131 @@
+package pir
+
+import (
+	"sort"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+// Each query recovers a whole column of the database, so fetching several
+// records takes one query per distinct column (index % M) among them.
+type MultiSecret[T matrix.Elem] struct {
+	secrets []*Secret[T]
+	indices [][]uint64 // records recovered by each query; nil for dummies
+}
+
+// Returns the distinct columns holding the given records, in increasing
+// order.
+func (Info *DBInfo) Columns(indices []uint64) []uint64 {
+	seen := make(map[uint64]bool)
+	var cols []uint64
+	for _, i := range indices {
+		if i >= Info.Num {
+			panic("Index out of range")
+		}
+		if col := i % Info.M; !seen[col] {
+			seen[col] = true
+			cols = append(cols, col)
+		}
+	}
+
+	sort.Slice(cols, func(a, b int) bool { return cols[a] < cols[b] })
+	return cols
+}
+
+// Builds one query per distinct column among the requested records.
+// Note that the number of queries reveals the number of distinct columns;
+// use QueryMultiPadded to hide it.
+func (c *Client[T]) QueryMulti(indices []uint64) (*MultiSecret[T], []*Query[T]) {
+	return c.QueryMultiPadded(indices, 0)
+}
+
+// Like QueryMulti, but pads with dummy queries to random columns so that
+// exactly k queries are sent. Panics if the records span more than k
+// columns.
+func (c *Client[T]) QueryMultiPadded(indices []uint64, k uint64) (*MultiSecret[T], []*Query[T]) {
+	byCol := make(map[uint64][]uint64)
+	for _, i := range indices {
+		col := i % c.dbinfo.M
+		byCol[col] = append(byCol[col], i)
+	}
+
+	cols := c.dbinfo.Columns(indices)
+	if k > 0 && uint64(len(cols)) > k {
+		panic("Too many columns for the padded number of queries")
+	}
+
+	s := new(MultiSecret[T])
+	var queries []*Query[T]
+	for _, col := range cols {
+		secret := c.PreprocessQuery()
+		queries = append(queries, c.QueryPreprocessed(col, secret))
+		s.secrets = append(s.secrets, secret)
+		s.indices = append(s.indices, byCol[col])
+	}
+
+	for uint64(len(queries)) < k {
+		secret := c.PreprocessQuery()
+		col := c.prg.Uint64() % c.dbinfo.M
+		queries = append(queries, c.QueryPreprocessed(col, secret))
+		s.secrets = append(s.secrets, secret)
+		s.indices = append(s.indices, nil)
+	}
+
+	return s, queries
+}
+
+// Returns the value of every requested record, keyed by index.
+func (c *Client[T]) RecoverMulti(s *MultiSecret[T], answers []*Answer[T]) map[uint64]uint64 {
+	if len(answers) != len(s.secrets) {
+		panic("Wrong number of answers")
+	}
+
+	out := make(map[uint64]uint64)
+	for q, secret := range s.secrets {
+		if len(s.indices[q]) == 0 {
+			continue
+		}
+
+		ans := c.unmask(secret, answers[q])
+		for _, i := range s.indices[q] {
+			out[i] = c.Decode(ans, i)
+		}
+	}
+
+	return out
+}
+
+// Answers all the queries in a single scan of the database.
+func (s *Server[T]) AnswerMulti(queries []*Query[T]) []*Answer[T] {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	rows := queries[0].Query.Rows()
+	batch := matrix.New[T](rows, uint64(len(queries)))
+	for c, q := range queries {
+		if q.Query.Rows() != rows || q.Query.Cols() != 1 {
+			panic("Queries of different sizes")
+		}
+		for r := uint64(0); r < rows; r++ {
+			batch.Set(r, uint64(c), q.Query.Get(r, 0))
+		}
+	}
+
+	var res *matrix.Matrix[T]
+	if s.db.Info.Squishing == 1 {
+		res = matrix.Mul(s.db.Data, batch)
+	} else {
+		res = matrix.MulPackedWith(s.db.Data, batch, s.db.Info.SquishParams())
+	}
+
+	answers := make([]*Answer[T], len(queries))
+	for c := range answers {
+		ans := matrix.New[T](res.Rows(), 1)
+		for r := uint64(0); r < res.Rows(); r++ {
+			ans.Set(r, 0, res.Get(r, uint64(c)))
+		}
+		answers[c] = &Answer[T]{ans}
+	}
+	return answers
+}
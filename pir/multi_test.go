@@ -0,0 +1,56 @@
+package pir
+
+import (
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+func testMulti[T matrix.Elem](t *testing.T, N uint64, d uint64, k uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+
+	server, client := testSetup(prg, db)
+
+	// Two records share a column, and one is requested twice
+	M := db.Info.M
+	indices := []uint64{3, 3 + M, N - 1, 7, 3}
+	cols := db.Info.Columns(indices)
+	if len(cols) != 3 {
+		t.Fatalf("Expected 3 columns, got %d", len(cols))
+	}
+
+	secret, queries := client.QueryMultiPadded(indices, k)
+	if k == 0 && uint64(len(queries)) != uint64(len(cols)) {
+		t.Fatalf("Sent %d queries for %d columns", len(queries), len(cols))
+	}
+	if k > 0 && uint64(len(queries)) != k {
+		t.Fatalf("Sent %d queries, padded to %d", len(queries), k)
+	}
+
+	vals := client.RecoverMulti(secret, server.AnswerMulti(queries))
+	if len(vals) != 4 {
+		t.Fatalf("Recovered %d records instead of 4", len(vals))
+	}
+	for _, i := range indices {
+		if vals[i] != db.GetElem(i) {
+			t.Fatalf("Querying index %d: Got %d instead of %d", i, vals[i], db.GetElem(i))
+		}
+	}
+}
+
+func TestMulti32(t *testing.T) {
+	testMulti[matrix.Elem32](t, uint64(1<<16), uint64(8), 0)
+}
+
+func TestMultiPadded32(t *testing.T) {
+	testMulti[matrix.Elem32](t, uint64(1<<16), uint64(8), 5)
+}
+
+func TestMulti64(t *testing.T) {
+	testMulti[matrix.Elem64](t, uint64(1<<14), uint64(32), 0)
+}
+
+func TestMultiPadded64(t *testing.T) {
+	testMulti[matrix.Elem64](t, uint64(1<<14), uint64(32), 4)
+}
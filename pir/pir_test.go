@@ -3,16 +3,58 @@ package pir
 import (
 	"bytes"
 	"encoding/gob"
+	"encoding/hex"
 	"fmt"
+	"io"
 	//"log"
+	"os"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/ryanleh/simplepir/lwe"
 	"github.com/ryanleh/simplepir/matrix"
 	"github.com/ryanleh/simplepir/rand"
 )
 
 const SEC_PARAM = uint64(1 << 10)
 
+// Randomness for a test, seeded from PIR_SEED (in hex) if set, or else at
+// random. The seed is logged if the test fails, so that rerunning it with
+// PIR_SEED replays the same databases and queries.
+func testPRG(t testing.TB) *rand.BufPRGReader {
+	key := rand.RandomPRGKey()
+	if env := os.Getenv("PIR_SEED"); env != "" {
+		b, err := hex.DecodeString(env)
+		if err != nil || len(b) != len(key) {
+			t.Fatalf("PIR_SEED must be %d hex-encoded bytes", len(key))
+		}
+		copy(key[:], b)
+	}
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("PIR_SEED=%x", key[:])
+		}
+	})
+	return rand.NewBufPRG(rand.NewPRG(key))
+}
+
+func testKey(prg *rand.BufPRGReader) *rand.PRGKey {
+	var key rand.PRGKey
+	if _, err := io.ReadFull(prg, key[:]); err != nil {
+		panic(err)
+	}
+	return &key
+}
+
+// A server and client whose randomness is drawn from 'prg'.
+func testSetup[T matrix.Elem](prg *rand.BufPRGReader, db *Database[T]) (*Server[T], *Client[T]) {
+	server := NewServerSeed(db, testKey(prg))
+	client := NewClientWithKey(server.Hint(), server.MatrixA(), db.Info, testKey(prg))
+	return server, client
+}
+
 func TestGobQuery(t *testing.T) {
 	m := matrix.New[matrix.Elem32](5, 5)
 	m.Set(1, 0, 0)
@@ -153,6 +195,9 @@ func runPIRmany[T matrix.Elem](t *testing.T, client *Client[T], server *Server[T
 	//log.Printf("vals: %v \n", vals)
 	for row := uint64(0); row < uint64(len(vals)); row++ {
 		index := row*db.Info.M + col_index
+		if index >= db.Info.Num {
+			break // padding
+		}
 		if db.GetElem(index) != vals[row] {
 			t.Fatalf("Querying index %d: Got %d instead of %d\n",
 				index, vals[row], db.GetElem(index))
@@ -161,7 +206,7 @@ func runPIRmany[T matrix.Elem](t *testing.T, client *Client[T], server *Server[T
 }
 
 func testSimplePir[T matrix.Elem](t *testing.T, N uint64, d uint64, index uint64) {
-	prg := rand.NewRandomBufPRG()
+	prg := testPRG(t)
 	db := NewDatabaseRandom[T](prg, N, d)
 	//for i := uint64(0); i<db.Data.Rows(); i++ {
 	//  for j := uint64(0); j<db.Data.Cols(); j++ {
@@ -173,41 +218,35 @@ func testSimplePir[T matrix.Elem](t *testing.T, N uint64, d uint64, index uint64
 	//db.Data.Print()
 	//log.Printf("===%v", db.Data.Get(0,0))
 
-	server := NewServer(db)
-	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+	server, client := testSetup(prg, db)
 
 	runPIR(t, client, server, db, index)
 }
 
 func testSimplePirMany[T matrix.Elem](t *testing.T, N uint64, d uint64, index uint64) {
-	prg := rand.NewRandomBufPRG()
+	prg := testPRG(t)
 	db := NewDatabaseRandom[T](prg, N, d)
 	//log.Printf("packing: %v", db.Info.Packing)
 
-	server := NewServer(db)
-	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+	server, client := testSetup(prg, db)
 
 	runPIRmany(t, client, server, db, index)
 }
 
 func testSimplePirCompressed[T matrix.Elem](t *testing.T, N uint64, d uint64, index uint64) {
-	prg := rand.NewRandomBufPRG()
+	prg := testPRG(t)
 	db := NewDatabaseRandom[T](prg, N, d)
 
-	seed := rand.RandomPRGKey()
-	server := NewServerSeed(db, seed)
-	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+	server, client := testSetup(prg, db)
 
 	runPIR(t, client, server, db, index)
 }
 
 func testSimplePirCompressedMany[T matrix.Elem](t *testing.T, N uint64, d uint64, index uint64) {
-	prg := rand.NewRandomBufPRG()
+	prg := testPRG(t)
 	db := NewDatabaseRandom[T](prg, N, d)
 
-	seed := rand.RandomPRGKey()
-	server := NewServerSeed(db, seed)
-	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+	server, client := testSetup(prg, db)
 
 	runPIRmany(t, client, server, db, index)
 }
@@ -298,3 +337,507 @@ func TestSimplePirBigDBCompressedMany32(t *testing.T) {
 func TestSimplePirBigDBCompressedMany64(t *testing.T) {
 	testSimplePirCompressedMany[matrix.Elem64](t, uint64(1<<25), uint64(18), 2)
 }
+
+func testRecoverColumn[T matrix.Elem](t *testing.T, N uint64, d uint64, index uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+
+	server, client := testSetup(prg, db)
+
+	secret, query := client.Query(index)
+	records := client.RecoverColumn(secret, server.Answer(query))
+
+	col := index % db.Info.M
+	want := (N - col + db.Info.M - 1) / db.Info.M
+	if uint64(len(records)) != want {
+		t.Fatalf("Got %d records, expected %d", len(records), want)
+	}
+	for row, r := range records {
+		if r.Index != uint64(row)*db.Info.M+col {
+			t.Fatalf("Record %d has index %d", row, r.Index)
+		}
+		if r.Value != db.GetElem(r.Index) {
+			t.Fatalf("Querying index %d: Got %d instead of %d", r.Index, r.Value, db.GetElem(r.Index))
+		}
+	}
+}
+
+func TestRecoverColumn32(t *testing.T) {
+	testRecoverColumn[matrix.Elem32](t, uint64(1<<12)+7, uint64(8), 5)
+}
+
+// Several Z_p elements per record
+func TestRecoverColumnLongRow32(t *testing.T) {
+	testRecoverColumn[matrix.Elem32](t, uint64(1<<12)+7, uint64(32), 0)
+}
+
+func TestRecoverColumnLongRow64(t *testing.T) {
+	testRecoverColumn[matrix.Elem64](t, uint64(1<<10)+3, uint64(64), 2)
+}
+
+// Clients with the same key make the same queries.
+func testClientKey[T matrix.Elem](t *testing.T, N uint64, d uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+	server := NewServerSeed(db, testKey(prg))
+
+	key := testKey(prg)
+	var queries []*Query[T]
+	for i := 0; i < 2; i++ {
+		client := NewClientWithKey(server.Hint(), server.MatrixA(), db.Info, key)
+		secret, query := client.Query(5)
+		if client.Recover(secret, server.Answer(query)) != db.GetElem(5) {
+			t.Fatal("Recovered wrong value")
+		}
+		queries = append(queries, query)
+	}
+	if !queries[0].Query.Equals(queries[1].Query) {
+		t.Fatal("Same key gave different queries")
+	}
+
+	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+	if _, query := client.Query(5); query.Query.Equals(queries[0].Query) {
+		t.Fatal("Random client repeated a query")
+	}
+}
+
+func TestClientKey32(t *testing.T) {
+	testClientKey[matrix.Elem32](t, uint64(1<<12), uint64(8))
+}
+
+func TestClientKey64(t *testing.T) {
+	testClientKey[matrix.Elem64](t, uint64(1<<12), uint64(8))
+}
+
+// Random small databases answer queries correctly, and store each record
+// exactly.
+func fuzzPIR[T matrix.Elem](f *testing.F) {
+	f.Add(uint16(0), uint8(1), []byte{1}, uint32(0))
+	f.Add(uint16(999), uint8(8), []byte("records"), uint32(500))
+	f.Add(uint16(1<<13+6), uint8(9), []byte{0xff}, uint32(1<<13+3)) // Num % M != 0
+	f.Add(uint16(100), uint8(T(0).Bitlen()-1), []byte{0xff, 0x00, 0x80}, uint32(99))
+
+	f.Fuzz(func(t *testing.T, num uint16, rowLength uint8, values []byte, index uint32) {
+		n := uint64(num)%(1<<14) + 1
+		d := uint64(rowLength)%T(0).Bitlen() + 1
+
+		vals := make([]T, n)
+		if len(values) > 0 {
+			for i := range vals {
+				var v uint64
+				for j := 0; j < 8; j++ {
+					v |= uint64(values[(8*i+j)%len(values)]) << (8 * j)
+				}
+				vals[i] = T(v & (1<<d - 1))
+			}
+		}
+
+		db := NewDatabase[T](n, d, vals)
+		for i, v := range vals {
+			if db.GetElem(uint64(i)) != uint64(v) {
+				t.Fatalf("Record %d stored as %d instead of %d", i, db.GetElem(uint64(i)), v)
+			}
+		}
+
+		prg := rand.NewBufPRG(rand.NewPRG(&rand.PRGKey{}))
+		server, client := testSetup(prg, db)
+		runPIR(t, client, server, db, uint64(index)%n)
+	})
+}
+
+func FuzzPIR32(f *testing.F) {
+	fuzzPIR[matrix.Elem32](f)
+}
+
+func FuzzPIR64(f *testing.F) {
+	fuzzPIR[matrix.Elem64](f)
+}
+
+func expectPanic(t *testing.T, want any, f func()) {
+	defer func() {
+		if r := recover(); r != want {
+			t.Fatalf("Expected panic %v, got %v", want, r)
+		}
+	}()
+	f()
+}
+
+func testSecretReuse[T matrix.Elem](t *testing.T, N uint64, d uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+
+	server, client := testSetup(prg, db)
+
+	secret := client.PreprocessQuery()
+	expectPanic(t, ErrSecretNotQueried, func() {
+		client.Recover(secret, &Answer[T]{matrix.Zeros[T](db.Info.L, 1)})
+	})
+
+	query := client.QueryPreprocessed(1, secret)
+	if secret.State() != SecretQueried {
+		t.Fatal("Secret not marked as queried")
+	}
+	expectPanic(t, ErrSecretReused, func() { client.QueryPreprocessed(2, secret) })
+
+	answer := server.Answer(query)
+	for i := 0; i < 2; i++ {
+		if client.Recover(secret, answer) != db.GetElem(1) {
+			t.Fatal("Recovered wrong value")
+		}
+	}
+	if secret.State() != SecretRecovered {
+		t.Fatal("Secret not marked as recovered")
+	}
+	expectPanic(t, ErrSecretReused, func() { client.QueryPreprocessed(1, secret) })
+}
+
+// The checked variants return the errors instead of panicking.
+func testSecretReuseChecked[T matrix.Elem](t *testing.T, N uint64, d uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+
+	server, client := testSetup(prg, db)
+
+	secret := client.PreprocessQuery()
+	if _, err := client.RecoverChecked(secret, &Answer[T]{matrix.Zeros[T](db.Info.L, 1)}); err != ErrSecretNotQueried {
+		t.Fatalf("Expected %v, got %v", ErrSecretNotQueried, err)
+	}
+
+	query, err := client.QueryPreprocessedChecked(1, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.QueryPreprocessedChecked(2, secret); err != ErrSecretReused {
+		t.Fatalf("Expected %v, got %v", ErrSecretReused, err)
+	}
+
+	if _, err := client.RecoverChecked(secret, &Answer[T]{matrix.Zeros[T](db.Info.L+1, 1)}); err == nil {
+		t.Fatal("Recovered an answer of the wrong shape")
+	}
+	if val, err := client.RecoverChecked(secret, server.Answer(query)); err != nil || val != db.GetElem(1) {
+		t.Fatalf("Recovered %d (%v) instead of %d", val, err, db.GetElem(1))
+	}
+}
+
+func TestSecretReuseChecked32(t *testing.T) {
+	testSecretReuseChecked[matrix.Elem32](t, uint64(1<<12), uint64(8))
+}
+
+func TestSecretReuseChecked64(t *testing.T) {
+	testSecretReuseChecked[matrix.Elem64](t, uint64(1<<12), uint64(8))
+}
+
+func TestSecretReuse32(t *testing.T) {
+	testSecretReuse[matrix.Elem32](t, uint64(1<<12), uint64(8))
+}
+
+func TestSecretReuse64(t *testing.T) {
+	testSecretReuse[matrix.Elem64](t, uint64(1<<12), uint64(8))
+}
+
+// Test SimplePIR correctness when the plaintext modulus p picks a
+// squishing layout other than the default.
+func testSimplePirSquish[T matrix.Elem](t *testing.T, N uint64, d uint64, p uint64, ratio uint64, index uint64) {
+	prg := testPRG(t)
+	info := NewDBInfo(T(0).Bitlen(), N, d)
+	params := lwe.NewParamsFixedP(T(0).Bitlen(), info.M, p)
+	db := NewDatabaseRandomFixedParams[T](prg, N, d, params)
+
+	server, client := testSetup(prg, db)
+
+	if db.Info.Squishing != ratio {
+		t.Fatalf("Squished %d values per element, expected %d", db.Info.Squishing, ratio)
+	}
+
+	runPIR(t, client, server, db, index)
+	runPIRmany(t, client, server, db, index)
+}
+
+func TestSimplePirSquish32(t *testing.T) {
+	testSimplePirSquish[matrix.Elem32](t, uint64(1<<16), uint64(8), 256, 4, 1000)
+}
+
+func TestSimplePirSquish64(t *testing.T) {
+	testSimplePirSquish[matrix.Elem64](t, uint64(1<<14), uint64(16), 1<<16, 4, 1000)
+}
+
+// Test SimplePIR correctness when the server keeps the database unsquished.
+func testSimplePirNoSquish[T matrix.Elem](t *testing.T, N uint64, d uint64, index uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+	db.Info.Squishing = 1
+
+	server, client := testSetup(prg, db)
+
+	if server.DB().Data.Cols() != db.Info.M {
+		t.Fatal("Database was squished")
+	}
+	if db.Info.Costs().QueryBytes != db.Info.M*T(0).Bitlen()/8 {
+		t.Fatal("Query was padded")
+	}
+
+	runPIR(t, client, server, db, index)
+	runPIRmany(t, client, server, db, index)
+}
+
+func TestSimplePirNoSquish32(t *testing.T) {
+	testSimplePirNoSquish[matrix.Elem32](t, uint64(1<<16), uint64(8), 1000)
+}
+
+func TestSimplePirNoSquish64(t *testing.T) {
+	testSimplePirNoSquish[matrix.Elem64](t, uint64(1<<14), uint64(16), 1000)
+}
+
+// Test SimplePIR correctness when no squishing layout fits the plaintext
+// modulus, so that the server falls back to the unsquished database.
+// NewParamsFixedP only accepts moduli that fit, so P is set by hand; 1-bit
+// records keep the noise small despite the large modulus.
+func testSimplePirSquishFallback[T matrix.Elem](t *testing.T, N uint64, index uint64) {
+	prg := testPRG(t)
+	info := NewDBInfo(T(0).Bitlen(), N, 1)
+	params := *info.Params
+	params.P = 1 << (T(0).Bitlen()/2 + 1)
+	params.Delta = 1 << (T(0).Bitlen()/2 - 1)
+	if _, ok := matrix.ChooseSquishParams[T](params.P); ok {
+		t.Fatalf("Modulus %d fits a squishing layout", params.P)
+	}
+	db := NewDatabaseRandomFixedParams[T](prg, N, 1, &params)
+
+	server, client := testSetup(prg, db)
+	if server.DB().Info.Squishing != 1 || server.DB().Data.Cols() != db.Info.M {
+		t.Fatal("Database was squished")
+	}
+
+	secret, query := client.Query(index)
+	answer := server.Answer(query)
+	if !answer.Answer.Equals(matrix.MulVec(server.DB().Data, query.Query)) {
+		t.Fatal("Answer does not match MulVec")
+	}
+	if val := client.Recover(secret, answer); val != db.GetElem(index) {
+		t.Fatalf("Querying index %d: Got %d instead of %d", index, val, db.GetElem(index))
+	}
+	runPIRmany(t, client, server, db, index)
+}
+
+func TestSimplePirSquishFallback32(t *testing.T) {
+	testSimplePirSquishFallback[matrix.Elem32](t, uint64(1<<16), 1000)
+}
+
+func TestSimplePirSquishFallback64(t *testing.T) {
+	testSimplePirSquishFallback[matrix.Elem64](t, uint64(1<<14), 1000)
+}
+
+// Benchmarks run on a random database of 2^LOG_N entries of D bits each.
+func benchParams() (uint64, uint64) {
+	logN, d := uint64(20), uint64(8)
+	if v, err := strconv.ParseUint(os.Getenv("LOG_N"), 10, 64); err == nil {
+		logN = v
+	}
+	if v, err := strconv.ParseUint(os.Getenv("D"), 10, 64); err == nil {
+		d = v
+	}
+	return uint64(1) << logN, d
+}
+
+func benchSetup[T matrix.Elem](b *testing.B) (*Database[T], *Server[T], *Client[T]) {
+	N, d := benchParams()
+	prg := rand.NewRandomBufPRG()
+	db := NewDatabaseRandom[T](prg, N, d)
+
+	server := NewServer(db)
+	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+
+	b.Logf("Database: %d entries of %d bits, L=%d M=%d", N, d, db.Info.L, db.Info.M)
+	return db, server, client
+}
+
+func reportRate(b *testing.B, info *DBInfo, start time.Time, batch_sz int) {
+	elapsed := time.Since(start) / time.Duration(b.N)
+	b.ReportMetric(throughput(info, elapsed, batch_sz), "MB/s")
+}
+
+func benchmarkNewServer[T matrix.Elem](b *testing.B) {
+	N, d := benchParams()
+	prg := rand.NewRandomBufPRG()
+	db := NewDatabaseRandom[T](prg, N, d)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		NewServer(db)
+	}
+	reportRate(b, db.Info, start, 1)
+}
+
+func BenchmarkNewServer32(b *testing.B) {
+	benchmarkNewServer[matrix.Elem32](b)
+}
+
+func BenchmarkNewServer64(b *testing.B) {
+	benchmarkNewServer[matrix.Elem64](b)
+}
+
+func benchmarkPreprocessQuery[T matrix.Elem](b *testing.B) {
+	db, _, client := benchSetup[T](b)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		client.PreprocessQuery()
+	}
+	reportRate(b, db.Info, start, 1)
+}
+
+func BenchmarkPreprocessQuery32(b *testing.B) {
+	benchmarkPreprocessQuery[matrix.Elem32](b)
+}
+
+func BenchmarkPreprocessQuery64(b *testing.B) {
+	benchmarkPreprocessQuery[matrix.Elem64](b)
+}
+
+func benchmarkAnswer[T matrix.Elem](b *testing.B) {
+	db, server, client := benchSetup[T](b)
+	_, query := client.Query(0)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		server.Answer(query)
+	}
+	reportRate(b, db.Info, start, 1)
+}
+
+func BenchmarkAnswer32(b *testing.B) {
+	benchmarkAnswer[matrix.Elem32](b)
+}
+
+func BenchmarkAnswer64(b *testing.B) {
+	benchmarkAnswer[matrix.Elem64](b)
+}
+
+// Answers a batch of 16 queries in one scan.
+func benchmarkAnswerMulti[T matrix.Elem](b *testing.B) {
+	db, server, client := benchSetup[T](b)
+	queries := make([]*Query[T], 16)
+	for i := range queries {
+		_, queries[i] = client.Query(uint64(i))
+	}
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		server.AnswerMulti(queries)
+	}
+	reportRate(b, db.Info, start, len(queries))
+}
+
+func BenchmarkAnswerMulti32(b *testing.B) {
+	benchmarkAnswerMulti[matrix.Elem32](b)
+}
+
+func BenchmarkAnswerMulti64(b *testing.B) {
+	benchmarkAnswerMulti[matrix.Elem64](b)
+}
+
+func benchmarkRecover[T matrix.Elem](b *testing.B) {
+	db, server, client := benchSetup[T](b)
+	secret, query := client.Query(0)
+	answer := server.Answer(query)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		client.Recover(secret, answer)
+	}
+	reportRate(b, db.Info, start, 1)
+}
+
+func BenchmarkRecover32(b *testing.B) {
+	benchmarkRecover[matrix.Elem32](b)
+}
+
+func BenchmarkRecover64(b *testing.B) {
+	benchmarkRecover[matrix.Elem64](b)
+}
+
+// Full online phase: query, answer and recover.
+func benchmarkPirSingle[T matrix.Elem](b *testing.B) {
+	db, server, client := benchSetup[T](b)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		index := uint64(i) % db.Info.Num
+		secret, query := client.Query(index)
+		val := client.Recover(secret, server.Answer(query))
+		if val != db.GetElem(index) {
+			b.Fatalf("(querying index %d): Got %d instead of %d\n",
+				index, val, db.GetElem(index))
+		}
+	}
+	reportRate(b, db.Info, start, 1)
+
+	costs := db.Info.Costs()
+	b.ReportMetric(float64(costs.HintBytes)/1024, "offline-KB")
+	b.ReportMetric(float64(costs.OnlineBytes())/1024, "online-KB")
+}
+
+func BenchmarkPirSingle32(b *testing.B) {
+	benchmarkPirSingle[matrix.Elem32](b)
+}
+
+func BenchmarkPirSingle64(b *testing.B) {
+	benchmarkPirSingle[matrix.Elem64](b)
+}
+
+// Answers batches of queries of increasing size, each in one scan, and logs
+// the throughput for each batch size to 'simple-batch.log'.
+func benchmarkPirBatch[T matrix.Elem](b *testing.B, batchSizes []int) {
+	db, server, client := benchSetup[T](b)
+
+	f, err := os.Create("simple-batch.log")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, batch_sz := range batchSizes {
+		queries := make([]*Query[T], batch_sz)
+		for j := range queries {
+			_, queries[j] = client.Query(uint64(j) % db.Info.Num)
+		}
+
+		// The closure runs once per ramp-up step: log the rate of the
+		// last, full run only
+		var rate float64
+		ok := b.Run(fmt.Sprintf("Batch%d", batch_sz), func(b *testing.B) {
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				server.AnswerMulti(queries)
+			}
+			elapsed := time.Since(start) / time.Duration(b.N)
+			rate = throughput(db.Info, elapsed, batch_sz)
+			b.ReportMetric(rate, "MB/s")
+		})
+		if ok {
+			fmt.Fprintf(f, "%d,%f\n", batch_sz, rate)
+		}
+	}
+}
+
+func BenchmarkPirBatch32(b *testing.B) {
+	benchmarkPirBatch[matrix.Elem32](b, []int{1, 2, 4, 8, 16, 32})
+}
+
+func BenchmarkPirBatch64(b *testing.B) {
+	benchmarkPirBatch[matrix.Elem64](b, []int{1, 2, 4, 8, 16, 32})
+}
+
+func BenchmarkPirBatchLarge32(b *testing.B) {
+	benchmarkPirBatch[matrix.Elem32](b, []int{64, 128, 256, 512})
+}
+
+func BenchmarkPirBatchLarge64(b *testing.B) {
+	benchmarkPirBatch[matrix.Elem64](b, []int{64, 128, 256, 512})
+}
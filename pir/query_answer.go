@@ -5,11 +5,14 @@ import (
 )
 
 import (
+	"errors"
+
 	"github.com/ryanleh/simplepir/matrix"
 )
 
 type Query[T matrix.Elem] struct {
-	Query *matrix.Matrix[T]
+	Query   *matrix.Matrix[T]
+	Version uint64 // DBInfo.Version of the database queried
 }
 
 type Secret[T matrix.Elem] struct {
@@ -17,6 +20,55 @@ type Secret[T matrix.Elem] struct {
 	secret *matrix.Matrix[T]
 	interm *matrix.Matrix[T]
 	index  uint64
+	state  SecretState
+}
+
+// Lifecycle of a Secret or SecretLHE. A secret may only be used for one
+// query: reusing it would corrupt the query and leak the difference
+// between the indices queried. Recovering the same answer again is fine.
+type SecretState int
+
+const (
+	SecretFresh     SecretState = iota // preprocessed, not yet used
+	SecretQueried                      // query built, awaiting an answer
+	SecretRecovered                    // answer recovered
+)
+
+// Errors on misuse of a secret, returned by the checked methods (e.g.
+// QueryPreprocessedChecked) and the values of the panics otherwise.
+var ErrSecretReused = errors.New("pir: secret already used for a query")
+var ErrSecretNotQueried = errors.New("pir: secret not used for a query yet")
+
+func (s SecretState) checkQuery() error {
+	if s != SecretFresh {
+		return ErrSecretReused
+	}
+	return nil
+}
+
+func (s SecretState) checkRecover() error {
+	if s == SecretFresh {
+		return ErrSecretNotQueried
+	}
+	return nil
+}
+
+func (s SecretState) markQueried() SecretState {
+	if err := s.checkQuery(); err != nil {
+		panic(err)
+	}
+	return SecretQueried
+}
+
+func (s SecretState) markRecovered() SecretState {
+	if err := s.checkRecover(); err != nil {
+		panic(err)
+	}
+	return SecretRecovered
+}
+
+func (s *Secret[T]) State() SecretState {
+	return s.state
 }
 
 type Answer[T matrix.Elem] struct {
@@ -26,6 +78,7 @@ type Answer[T matrix.Elem] struct {
 func (q *Query[T]) SelectRows(start, num, squishing uint64) *Query[T] {
 	res := new(Query[T])
 	res.Query = q.Query.RowsDeepCopy(start, num)
+	res.Version = q.Version
 
 	r, c := res.Query.Rows(), res.Query.Cols()
 	if (r*c)%squishing != 0 {
@@ -0,0 +1,189 @@
+package pir
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+// Queues queries in front of a server, and answers those arriving within a
+// short window together in one scan of the database (see AnswerMulti), so
+// that throughput rises with load. At most 'maxInFlight' scans run at once;
+// while all are busy, the next batch keeps growing up to 'maxBatch' queries.
+type Scheduler[T matrix.Elem] struct {
+	server   *Server[T]
+	window   time.Duration
+	maxBatch int
+
+	queue    chan *pending[T]
+	inflight chan struct{}
+	scans    uint64
+
+	mu      sync.RWMutex
+	closed  bool
+	closing chan struct{} // closed by Close, to wake blocked senders
+	senders sync.WaitGroup
+	once    sync.Once
+	done    chan struct{}
+}
+
+type pending[T matrix.Elem] struct {
+	query *Query[T]
+	out   chan *Answer[T]
+}
+
+func NewScheduler[T matrix.Elem](server *Server[T], window time.Duration, maxBatch, maxInFlight int) *Scheduler[T] {
+	if maxBatch < 1 || maxInFlight < 1 {
+		panic("Scheduler needs room for at least one query")
+	}
+
+	s := &Scheduler[T]{
+		server:   server,
+		window:   window,
+		maxBatch: maxBatch,
+		queue:    make(chan *pending[T], maxBatch),
+		inflight: make(chan struct{}, maxInFlight),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+
+	return s
+}
+
+var ErrSchedulerClosed = errors.New("pir: scheduler is closed")
+
+// Queues the query; its answer is sent on the returned channel. Returns an
+// error if the query does not match the database, or the scheduler is
+// closed, so that servers can reject malformed client queries.
+func (s *Scheduler[T]) Submit(query *Query[T]) (<-chan *Answer[T], error) {
+	if err := s.server.CheckQuery(query); err != nil {
+		return nil, err
+	}
+
+	// Register as a sender under the lock, but send without it, so that
+	// Close does not wait behind a full queue.
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, ErrSchedulerClosed
+	}
+	s.senders.Add(1)
+	s.mu.RUnlock()
+	defer s.senders.Done()
+
+	p := &pending[T]{query: query, out: make(chan *Answer[T], 1)}
+	select {
+	case s.queue <- p:
+		return p.out, nil
+	case <-s.closing:
+		return nil, ErrSchedulerClosed
+	}
+}
+
+// Satisfies Answerer, for trusted queries. Panics where Submit returns an
+// error.
+func (s *Scheduler[T]) Answer(query *Query[T]) *Answer[T] {
+	out, err := s.Submit(query)
+	if err != nil {
+		panic(err)
+	}
+	return <-out
+}
+
+// Number of database scans so far.
+func (s *Scheduler[T]) Scans() uint64 {
+	return atomic.LoadUint64(&s.scans)
+}
+
+// Stops accepting queries, and waits until all queued ones are answered.
+func (s *Scheduler[T]) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.closing)
+	}
+	s.mu.Unlock()
+
+	// No sender registers once closed is set; wait out the blocked ones
+	s.senders.Wait()
+	s.once.Do(func() { close(s.queue) })
+	<-s.done
+}
+
+func (s *Scheduler[T]) run() {
+	var wg sync.WaitGroup
+	queue := s.queue
+
+	for queue != nil {
+		first, ok := <-queue
+		if !ok {
+			break
+		}
+		batch := []*pending[T]{first}
+
+		// Collect the queries arriving within the window
+		timer := time.NewTimer(s.window)
+	collect:
+		for len(batch) < s.maxBatch {
+			select {
+			case p, ok := <-queue:
+				if !ok {
+					queue = nil
+					break collect
+				}
+				batch = append(batch, p)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		// Wait for a free slot, still growing the batch meanwhile
+	wait:
+		for {
+			var more <-chan *pending[T]
+			if len(batch) < s.maxBatch {
+				more = queue
+			}
+
+			select {
+			case s.inflight <- struct{}{}:
+				break wait
+			case p, ok := <-more:
+				if !ok {
+					queue = nil
+					continue
+				}
+				batch = append(batch, p)
+			}
+		}
+
+		wg.Add(1)
+		go func(batch []*pending[T]) {
+			defer wg.Done()
+			defer func() { <-s.inflight }()
+			s.answer(batch)
+		}(batch)
+	}
+
+	wg.Wait()
+	close(s.done)
+}
+
+func (s *Scheduler[T]) answer(batch []*pending[T]) {
+	queries := make([]*Query[T], len(batch))
+	for i, p := range batch {
+		queries[i] = p.query
+	}
+
+	answers := s.server.AnswerMulti(queries)
+	atomic.AddUint64(&s.scans, 1)
+
+	for i, p := range batch {
+		p.out <- answers[i]
+	}
+}
@@ -0,0 +1,143 @@
+package pir
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+func testScheduler[T matrix.Elem](t *testing.T, N uint64, d uint64, squish bool) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+	if !squish {
+		db.Info.Squishing = 1
+	}
+
+	server, client := testSetup(prg, db)
+	sched := NewScheduler(server, 50*time.Millisecond, 8, 2)
+
+	// The client is not safe for concurrent use: query first
+	num := 20
+	secrets := make([]*Secret[T], num)
+	queries := make([]*Query[T], num)
+	for i := range queries {
+		secrets[i], queries[i] = client.Query(uint64(i*37) % N)
+	}
+
+	answers := make([]*Answer[T], num)
+	var wg sync.WaitGroup
+	for i := range queries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			answers[i] = sched.Answer(queries[i])
+		}(i)
+	}
+	wg.Wait()
+	sched.Close()
+
+	for i := range answers {
+		index := uint64(i*37) % N
+		if val := client.Recover(secrets[i], answers[i]); val != db.GetElem(index) {
+			t.Fatalf("Querying index %d: Got %d instead of %d", index, val, db.GetElem(index))
+		}
+	}
+
+	if sched.Scans() >= uint64(num) || sched.Scans() < uint64(num/8) {
+		t.Fatalf("Answered %d queries in %d scans", num, sched.Scans())
+	}
+
+	if _, err := sched.Submit(queries[0]); err != ErrSchedulerClosed {
+		t.Fatalf("Submit after Close: got %v", err)
+	}
+	if _, err := sched.Submit(&Query[T]{Query: matrix.Zeros[T](3, 1)}); err != ErrQueryShape {
+		t.Fatalf("Submit of a mis-shaped query: got %v", err)
+	}
+	if _, err := sched.Submit(nil); err != ErrQueryShape {
+		t.Fatalf("Submit of a nil query: got %v", err)
+	}
+}
+
+// Close must not stall behind senders blocked on a full queue.
+func testSchedulerCloseBlocked[T matrix.Elem](t *testing.T, N uint64, d uint64) {
+	prg := testPRG(t)
+	db := NewDatabaseRandom[T](prg, N, d)
+	server, client := testSetup(prg, db)
+	sched := NewScheduler(server, time.Millisecond, 1, 1)
+
+	// Hold the only scan slot: the first query waits for it, the second
+	// fills the queue, and the rest block in Submit
+	sched.inflight <- struct{}{}
+	_, query := client.Query(0)
+	first, err := sched.Submit(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for len(sched.queue) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	num := 5
+	errs := make(chan error, num)
+	for i := 0; i < num; i++ {
+		_, query := client.Query(uint64(i + 1))
+		go func() {
+			out, err := sched.Submit(query)
+			if err == nil {
+				<-out
+			}
+			errs <- err
+		}()
+	}
+	for len(sched.queue) < cap(sched.queue) {
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		sched.Close()
+		close(closed)
+	}()
+
+	// The blocked senders give up while the scan slot is still held
+	for i := 0; i < num-1; i++ {
+		select {
+		case err := <-errs:
+			if err != ErrSchedulerClosed {
+				t.Fatalf("Submit: got %v", err)
+			}
+		case <-time.After(time.Minute):
+			t.Fatal("Close stalled behind blocked senders")
+		}
+	}
+
+	// Queued queries are still answered
+	<-sched.inflight
+	<-closed
+	<-first
+	if err := <-errs; err != nil {
+		t.Fatalf("Submit: got %v", err)
+	}
+}
+
+func TestScheduler32(t *testing.T) {
+	testScheduler[matrix.Elem32](t, uint64(1<<16), uint64(8), true)
+}
+
+func TestSchedulerNoSquish32(t *testing.T) {
+	testScheduler[matrix.Elem32](t, uint64(1<<16), uint64(8), false)
+}
+
+func TestScheduler64(t *testing.T) {
+	testScheduler[matrix.Elem64](t, uint64(1<<14), uint64(32), true)
+}
+
+func TestSchedulerCloseBlocked32(t *testing.T) {
+	testSchedulerCloseBlocked[matrix.Elem32](t, uint64(1<<10), uint64(8))
+}
+
+func TestSchedulerCloseBlocked64(t *testing.T) {
+	testSchedulerCloseBlocked[matrix.Elem64](t, uint64(1<<10), uint64(32))
+}
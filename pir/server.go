@@ -1,6 +1,8 @@
 package pir
 
 import (
+	"errors"
+
 	"github.com/ryanleh/simplepir/lwe"
 	"github.com/ryanleh/simplepir/matrix"
 	"github.com/ryanleh/simplepir/rand"
@@ -24,15 +26,14 @@ func NewServerSeed[T matrix.Elem](db *Database[T], seed *rand.PRGKey) *Server[T]
 
 func setupServer[T matrix.Elem](db *Database[T], matrixAseed *rand.PRGKey) *Server[T] {
 
-	src := rand.NewBufPRG(rand.NewPRG(matrixAseed))
-	matrixA := matrix.Rand[T](src, db.Info.M, db.Info.Params.N, 0)
+	src := []matrix.IoRandSource{rand.NewBufPRG(rand.NewPRG(matrixAseed))}
+	matrixAseeded := matrix.NewSeeded[T](src, []uint64{db.Info.M}, db.Info.Params.N)
 
 	s := &Server[T]{
 		params:      db.Info.Params,
 		matrixAseed: matrixAseed,
 		db:          db.Copy(),
-		//hint:        matrix.MulSeededRight(db.Data, matrixAseeded),
-		hint: matrix.Mul(db.Data, matrixA),
+		hint:        matrix.MulSeededRight(db.Data, matrixAseeded),
 	}
 
 	s.db.Squish()
@@ -68,6 +69,26 @@ func (s *Server[T]) Get(i uint64) uint64 {
 	return s.db.GetElem(i)
 }
 
+// Length of a query: queries are padded to the width of the packed
+// database.
+func (s *Server[T]) QueryRows() uint64 {
+	return s.db.Data.Cols() * s.db.Info.Squishing
+}
+
+var ErrQueryShape = errors.New("pir: query does not match the database")
+
+// Returns ErrQueryShape unless the query can be answered, for queries from
+// untrusted clients; Answer panics on those.
+func (s *Server[T]) CheckQuery(query *Query[T]) error {
+	if query == nil || query.Query == nil || query.Query.Rows() != s.QueryRows() || query.Query.Cols() != 1 {
+		return ErrQueryShape
+	}
+	return nil
+}
+
 func (s *Server[T]) Answer(query *Query[T]) *Answer[T] {
-	return &Answer[T]{matrix.MulVecPacked(s.db.Data, query.Query)}
+	if s.db.Info.Squishing == 1 {
+		return &Answer[T]{matrix.MulVec(s.db.Data, query.Query)}
+	}
+	return &Answer[T]{matrix.MulVecPackedWith(s.db.Data, query.Query, s.db.Info.SquishParams())}
 }
@@ -0,0 +1,61 @@
+package similarity
+
+import (
+	"math"
+)
+
+// Maps float embedding coordinates in [-1, 1] to Z_p, such that the inner
+// product of two quantized embeddings never wraps around mod p.
+type Quantizer struct {
+	P     uint64  // plaintext modulus
+	Dim   uint64  // embedding dimension
+	Scale float64 // multiplier applied to each coordinate before rounding
+}
+
+func NewQuantizer(p, dim uint64) *Quantizer {
+	if dim == 0 {
+		panic("Empty embeddings")
+	}
+
+	// Need dim * scale^2 < p/2, so that scores can be decoded as signed values.
+	scale := math.Floor(math.Sqrt(float64(p/2-1) / float64(dim)))
+	if scale < 1 {
+		panic("Embedding dimension too large for plaintext modulus")
+	}
+
+	return &Quantizer{
+		P:     p,
+		Dim:   dim,
+		Scale: scale,
+	}
+}
+
+func (q *Quantizer) Quantize(x float64) uint64 {
+	x = math.Max(-1, math.Min(1, x))
+	v := int64(math.Round(x * q.Scale))
+
+	p := int64(q.P)
+	return uint64(((v % p) + p) % p)
+}
+
+func (q *Quantizer) QuantizeVec(emb []float64) []uint64 {
+	if uint64(len(emb)) != q.Dim {
+		panic("Embedding dimension mismatch")
+	}
+
+	out := make([]uint64, len(emb))
+	for i, x := range emb {
+		out[i] = q.Quantize(x)
+	}
+	return out
+}
+
+// Interprets an inner product mod p as a signed value and undoes the scaling.
+func (q *Quantizer) DecodeScore(v uint64) float64 {
+	v %= q.P
+	signed := float64(v)
+	if v >= q.P/2 {
+		signed -= float64(q.P)
+	}
+	return signed / (q.Scale * q.Scale)
+}
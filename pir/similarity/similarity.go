@@ -0,0 +1,153 @@
+// Package similarity implements private inner-product search over
+// embeddings, using the linearly homomorphic mode of SimplePIR.
+//
+// Each embedding is quantized into Z_p and stored as one row of a
+// Database[T] whose width is the embedding dimension. The client sends its
+// encrypted query embedding with QueryLHE, the server answers as usual, and
+// the client decodes one score per stored embedding. The client can then
+// fetch the top-k matching documents from a second database with regular
+// PIR queries.
+package similarity
+
+import (
+	"math/bits"
+	"sort"
+)
+
+import (
+	"github.com/ryanleh/simplepir/lwe"
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+// LHE requires p | q, so use the largest power of two below the plaintext
+// modulus supported for 'dim' samples.
+func NewParams(logq, dim uint64) *lwe.Params {
+	params := lwe.NewParams(logq, dim)
+	if params == nil {
+		panic("Could not find LWE Params")
+	}
+
+	params = lwe.NewParamsFixedP(logq, dim, pir.PrevPowerOfTwo(params.P))
+	if params == nil {
+		panic("Could not find LWE Params")
+	}
+
+	return params
+}
+
+func QuantizerFor(info *pir.DBInfo) *Quantizer {
+	return NewQuantizer(info.P(), info.M)
+}
+
+// Lays out the quantized embeddings so that embedding i is row i of the
+// database.
+func NewDatabase[T matrix.Elem](embeddings [][]float64) (*pir.Database[T], *Quantizer) {
+	if len(embeddings) == 0 {
+		panic("Empty database!")
+	}
+
+	dim := uint64(len(embeddings[0]))
+	params := NewParams(T(0).Bitlen(), dim)
+	q := NewQuantizer(params.P, dim)
+
+	vals := make([]T, 0, uint64(len(embeddings))*dim)
+	for _, emb := range embeddings {
+		for _, v := range q.QuantizeVec(emb) {
+			vals = append(vals, T(v))
+		}
+	}
+
+	rowLength := uint64(bits.Len64(params.P) - 1)
+	db := pir.NewDatabaseFixedParams[T](uint64(len(vals)), rowLength, vals, params)
+
+	if db.Info.Ne != 1 || db.Info.L != uint64(len(embeddings)) {
+		panic("Bad database layout")
+	}
+
+	return db, q
+}
+
+type Client[T matrix.Elem] struct {
+	pir   *pir.Client[T]
+	quant *Quantizer
+	num   uint64
+}
+
+func NewClient[T matrix.Elem](hint *matrix.Matrix[T], matrixAseed *rand.PRGKey, dbinfo *pir.DBInfo) *Client[T] {
+	return &Client[T]{
+		pir:   pir.NewClient(hint, matrixAseed, dbinfo),
+		quant: QuantizerFor(dbinfo),
+		num:   dbinfo.Num / dbinfo.M,
+	}
+}
+
+func (c *Client[T]) Quantizer() *Quantizer {
+	return c.quant
+}
+
+func (c *Client[T]) Query(emb []float64) (*pir.SecretLHE[T], *pir.Query[T]) {
+	arr := matrix.Zeros[T](c.quant.Dim, 1)
+	for i, v := range c.quant.QuantizeVec(emb) {
+		arr.Set(uint64(i), 0, T(v))
+	}
+
+	return c.pir.QueryLHE(arr)
+}
+
+// Returns the (approximate) inner product of the query with every
+// stored embedding.
+func (c *Client[T]) Scores(s *pir.SecretLHE[T], ans *pir.Answer[T]) []float64 {
+	vals := c.pir.RecoverManyLHE(s, ans)
+
+	out := make([]float64, c.num)
+	for i := range out {
+		out[i] = c.quant.DecodeScore(uint64(vals.Get(uint64(i), 0)))
+	}
+
+	return out
+}
+
+// Returns the indices of the k highest scores, best first. Ties are
+// broken by lower index.
+func TopK(scores []float64, k int) []uint64 {
+	idx := make([]uint64, len(scores))
+	for i := range idx {
+		idx[i] = uint64(i)
+	}
+
+	sort.SliceStable(idx, func(a, b int) bool {
+		return scores[idx[a]] > scores[idx[b]]
+	})
+
+	if k < len(idx) {
+		idx = idx[:k]
+	}
+	return idx
+}
+
+// Second round: one regular PIR query per index against the document
+// database. Note that the server learns how many documents are fetched.
+func QueryDocuments[T matrix.Elem](docs *pir.Client[T], indices []uint64) ([]*pir.Secret[T], []*pir.Query[T]) {
+	secrets := make([]*pir.Secret[T], len(indices))
+	queries := make([]*pir.Query[T], len(indices))
+	for i, index := range indices {
+		secrets[i], queries[i] = docs.Query(index)
+	}
+
+	return secrets, queries
+}
+
+func RecoverDocuments[T matrix.Elem](docs *pir.Client[T], secrets []*pir.Secret[T], answers []*pir.Answer[T]) []uint64 {
+	if len(secrets) != len(answers) {
+		panic("Parameter mismatch")
+	}
+
+	out := make([]uint64, len(secrets))
+	for i := range secrets {
+		out[i] = docs.Recover(secrets[i], answers[i])
+	}
+
+	return out
+}
@@ -0,0 +1,107 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/pir"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func randomEmbeddings(prg *rand.BufPRGReader, num, dim int) [][]float64 {
+	r := prg.MathRand()
+	out := make([][]float64, num)
+	for i := range out {
+		out[i] = make([]float64, dim)
+		for j := range out[i] {
+			out[i][j] = 2*r.Float64() - 1
+		}
+	}
+	return out
+}
+
+func testSimilarity[T matrix.Elem](t *testing.T, num, dim, k int) {
+	prg := rand.NewRandomBufPRG()
+	embeddings := randomEmbeddings(prg, num, dim)
+	query := randomEmbeddings(prg, 1, dim)[0]
+
+	db, q := NewDatabase[T](embeddings)
+	server := pir.NewServer(db)
+	client := NewClient(server.Hint(), server.MatrixA(), db.Info)
+
+	if *client.Quantizer() != *q {
+		t.Fatal("Quantizer mismatch")
+	}
+
+	secret, qu := client.Query(query)
+	scores := client.Scores(secret, server.Answer(qu))
+
+	if len(scores) != num {
+		t.Fatalf("Got %d scores instead of %d", len(scores), num)
+	}
+
+	qVec := q.QuantizeVec(query)
+	for i, emb := range embeddings {
+		should_be := uint64(0)
+		for j, v := range q.QuantizeVec(emb) {
+			should_be += v * qVec[j]
+		}
+
+		if q.DecodeScore(should_be) != scores[i] {
+			t.Fatalf("Embedding %d: Got score %f instead of %f",
+				i, scores[i], q.DecodeScore(should_be))
+		}
+	}
+
+	// Second round: fetch the documents of the best matches.
+	docVals := make([]T, num)
+	for i := range docVals {
+		docVals[i] = T(1000 + i)
+	}
+	docDB := pir.NewDatabase[T](uint64(num), 16, docVals)
+	docServer := pir.NewServer(docDB)
+	docClient := pir.NewClient(docServer.Hint(), docServer.MatrixA(), docDB.Info)
+
+	top := TopK(scores, k)
+	if len(top) != k {
+		t.Fatalf("Got %d results instead of %d", len(top), k)
+	}
+	for i := 1; i < len(top); i++ {
+		if scores[top[i-1]] < scores[top[i]] {
+			t.Fatal("Results are not sorted")
+		}
+	}
+
+	secrets, queries := QueryDocuments(docClient, top)
+	answers := make([]*pir.Answer[T], len(queries))
+	for i, query := range queries {
+		answers[i] = docServer.Answer(query)
+	}
+
+	docs := RecoverDocuments(docClient, secrets, answers)
+	for i, index := range top {
+		if docs[i] != uint64(docVals[index]) {
+			t.Fatalf("Document %d: Got %d instead of %d", index, docs[i], docVals[index])
+		}
+	}
+}
+
+func TestSimilarity32(t *testing.T) {
+	testSimilarity[matrix.Elem32](t, 100, 16, 5)
+}
+
+func TestSimilarity64(t *testing.T) {
+	testSimilarity[matrix.Elem64](t, 100, 64, 5)
+}
+
+func TestQuantizer(t *testing.T) {
+	q := NewQuantizer(512, 16)
+
+	if q.Quantize(0) != 0 || q.Quantize(2) != uint64(q.Scale) {
+		t.Fail()
+	}
+
+	if q.DecodeScore(q.Quantize(-1)) != -1/q.Scale {
+		t.Fatalf("Got %f", q.DecodeScore(q.Quantize(-1)))
+	}
+}
@@ -0,0 +1,122 @@
+package pir
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+// Sparse databases, for ids from a huge space of which few are populated:
+// each id is cuckoo hashed into one of its candidate slots, among about 1.5
+// slots per id. A slot holds a flag, the full id as a tag, so that there
+// are no false positives, and the value. To look an id up, the client
+// queries all of its candidate slots.
+type SparseLayout struct {
+	NumSlots uint64
+	Key      rand.PRGKey // public hash key
+}
+
+// Bytes of the flag and tag in each slot.
+const sparseHeaderBytes = 9
+
+// Number of hash keys tried before giving up.
+const sparseMaxKeys = 10
+
+// Values are at most valueBytes long, and shorter ones are zero-padded.
+func NewSparseDatabase[T matrix.Elem](values map[uint64][]byte, valueBytes uint64) (*Database[T], *SparseLayout) {
+	if len(values) == 0 {
+		panic("Empty database!")
+	}
+
+	ids := make([]uint64, 0, len(values))
+	for id, v := range values {
+		if uint64(len(v)) > valueBytes {
+			panic("Value too long")
+		}
+		ids = append(ids, id)
+	}
+
+	l := &SparseLayout{NumSlots: (3*uint64(len(ids)) + 1) / 2}
+	if l.NumSlots < cuckooHashes {
+		l.NumSlots = cuckooHashes
+	}
+
+	prg := rand.NewRandomBufPRG()
+	var table map[uint64]uint64
+	for tries, ok := 0, false; !ok; tries++ {
+		if tries == sparseMaxKeys {
+			panic("Could not place the ids")
+		}
+		l.Key = *rand.RandomPRGKey()
+		table, ok = l.hasher().insert(ids, prg)
+	}
+
+	slots := make([][]byte, l.NumSlots)
+	for slot, id := range table {
+		buf := make([]byte, sparseHeaderBytes+valueBytes)
+		buf[0] = 1
+		binary.LittleEndian.PutUint64(buf[1:], id)
+		copy(buf[sparseHeaderBytes:], values[id])
+		slots[slot] = buf
+	}
+
+	return NewDatabaseBytes[T](sparseHeaderBytes+valueBytes, slots), l
+}
+
+func (l *SparseLayout) hasher() *cuckooHasher {
+	return newCuckooHasher(&l.Key, l.NumSlots)
+}
+
+// The slots where 'id' may be stored.
+func (l *SparseLayout) Slots(id uint64) []uint64 {
+	return l.hasher().buckets(id)
+}
+
+type SparseSecret[T matrix.Elem] struct {
+	id      uint64
+	secrets []*Secret[T]
+}
+
+// Always sends one query per candidate slot, whether or not 'id' is
+// populated.
+func (c *Client[T]) QuerySparse(l *SparseLayout, id uint64) (*SparseSecret[T], []*Query[T]) {
+	s := &SparseSecret[T]{id: id}
+	var queries []*Query[T]
+	for _, slot := range l.Slots(id) {
+		secret, query := c.Query(slot)
+		s.secrets = append(s.secrets, secret)
+		queries = append(queries, query)
+	}
+	return s, queries
+}
+
+// Returns the value of the id, or false if it is not populated.
+func (c *Client[T]) RecoverSparse(s *SparseSecret[T], answers []*Answer[T]) ([]byte, bool) {
+	if len(answers) != len(s.secrets) {
+		panic("Expected one answer per query")
+	}
+
+	var tag [sparseHeaderBytes]byte
+	tag[0] = 1
+	binary.LittleEndian.PutUint64(tag[1:], s.id)
+
+	var out []byte
+	for i, secret := range s.secrets {
+		buf := c.RecoverBytes(secret, answers[i])
+		if out == nil && bytes.Equal(buf[:sparseHeaderBytes], tag[:]) {
+			out = buf[sparseHeaderBytes:]
+		}
+	}
+	return out, out != nil
+}
+
+func (c *Client[T]) GetSparse(l *SparseLayout, id uint64, server Answerer[T]) ([]byte, bool) {
+	s, queries := c.QuerySparse(l, id)
+	answers := make([]*Answer[T], len(queries))
+	for i, q := range queries {
+		answers[i] = server.Answer(q)
+	}
+	return c.RecoverSparse(s, answers)
+}
@@ -0,0 +1,53 @@
+package pir
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+func testSparse[T matrix.Elem](t *testing.T, num uint64, valueBytes uint64) {
+	prg := testPRG(t)
+	values := make(map[uint64][]byte)
+	var ids []uint64
+	for uint64(len(values)) < num {
+		id := prg.Uint64() % (1 << 32)
+		if _, ok := values[id]; !ok {
+			values[id] = randomBytes(prg, valueBytes)
+			ids = append(ids, id)
+		}
+	}
+	values[ids[1]] = values[ids[1]][:valueBytes/2] // shorter values are zero-padded
+
+	db, layout := NewSparseDatabase[T](values, valueBytes)
+	if db.Info.Num != layout.NumSlots || layout.NumSlots > 2*num {
+		t.Fatalf("%d slots for %d ids", layout.NumSlots, num)
+	}
+
+	server, client := testSetup(prg, db)
+
+	for _, id := range []uint64{ids[0], ids[1], ids[num-1]} {
+		want := make([]byte, valueBytes)
+		copy(want, values[id])
+		got, ok := client.GetSparse(layout, id, server)
+		if !ok || !bytes.Equal(got, want) {
+			t.Fatalf("Querying id %d: Got %x (%v) instead of %x", id, got, ok, want)
+		}
+	}
+
+	// Ids outside of the table are never found
+	for _, id := range []uint64{1 << 32, 1<<64 - 1} {
+		if got, ok := client.GetSparse(layout, id, server); ok {
+			t.Fatalf("Querying missing id %d: Got %x", id, got)
+		}
+	}
+}
+
+func TestSparse32(t *testing.T) {
+	testSparse[matrix.Elem32](t, 1<<12, 8)
+}
+
+func TestSparse64(t *testing.T) {
+	testSparse[matrix.Elem64](t, 1<<12, 8)
+}
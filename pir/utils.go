@@ -1,6 +1,6 @@
 package pir
 
-import "math"
+import "math/big"
 
 import "github.com/ryanleh/simplepir/matrix"
 
@@ -23,10 +23,20 @@ func Reconstruct_from_base_p(p uint64, vals []uint64) uint64 {
 	return res
 }
 
-// Returns how many entries in Z_p are needed to represent an element in Z_q
+// Returns how many entries in Z_p are needed to represent an element in Z_q,
+// i.e. the smallest n with p^n >= q. Computed exactly, since floating-point
+// logarithms are off by one for some large p.
 func Compute_num_entries_base_p(p, log_q uint64) uint64 {
-	log_p := math.Log2(float64(p))
-	return uint64(math.Ceil(float64(log_q) / log_p))
+	if p < 2 {
+		panic("Plaintext modulus too small")
+	}
+
+	n := uint64(0)
+	bigP := new(big.Int).SetUint64(p)
+	for pow := big.NewInt(1); uint64(pow.BitLen()) <= log_q; n++ {
+		pow.Mul(pow, bigP)
+	}
+	return n
 }
 
 func PrevPowerOfTwo(v uint64) uint64 {
@@ -0,0 +1,211 @@
+package pir
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ryanleh/simplepir/lwe"
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+// Databases of byte records: each record is a little-endian integer of
+// 8*recordBytes bits, decomposed into Ne base-p digits like other records,
+// so records may be longer than an element.
+
+func NewDatabaseBytes[T matrix.Elem](recordBytes uint64, records [][]byte) *Database[T] {
+	info := NewDBInfo(T(0).Bitlen(), uint64(len(records)), 8*recordBytes)
+	return NewDatabaseBytesFixedParams[T](recordBytes, records, info.Params)
+}
+
+func NewDatabaseBytesFixedParams[T matrix.Elem](recordBytes uint64, records [][]byte, params *lwe.Params) *Database[T] {
+	db := new(Database[T])
+	db.Info = NewDBInfoFixedParams(uint64(len(records)), 8*recordBytes, params, true)
+	db.Data = matrix.Zeros[T](db.Info.L, db.Info.M)
+
+	for i, rec := range records {
+		if uint64(len(rec)) > recordBytes {
+			panic("Record too long")
+		}
+
+		digits := bytesToBaseP(rec, db.Info.P(), db.Info.Ne)
+		for j, d := range digits {
+			db.Data.Set((uint64(i)/db.Info.M)*db.Info.Ne+uint64(j),
+				uint64(i)%db.Info.M, T(d))
+		}
+	}
+
+	return db
+}
+
+func (db *Database[T]) GetBytes(i uint64) []byte {
+	if i >= db.Info.Num {
+		panic("Index out of range")
+	}
+
+	cols := db.Data.Cols()
+	col := i % cols
+	row := i / cols
+
+	var vals []uint64
+	for j := row * db.Info.Ne; j < (row+1)*db.Info.Ne; j++ {
+		vals = append(vals, uint64(db.Data.Get(j, col)))
+	}
+
+	return basePToBytes(vals, db.Info.P(), db.Info.RowLength/8)
+}
+
+func (c *Client[T]) DecodeBytes(ans *matrix.Matrix[T], index uint64) []byte {
+	var vals []uint64
+	row := index / c.dbinfo.M
+
+	for j := row * c.dbinfo.Ne; j < (row+1)*c.dbinfo.Ne; j++ {
+		vals = append(vals, c.params.Round(uint64(ans.Get(j, 0))))
+	}
+
+	return basePToBytes(vals, c.dbinfo.P(), c.dbinfo.RowLength/8)
+}
+
+func (c *Client[T]) RecoverBytes(s *Secret[T], ansIn *Answer[T]) []byte {
+	return c.DecodeBytes(c.unmask(s, ansIn), s.index)
+}
+
+func bytesToBaseP(rec []byte, p, ne uint64) []uint64 {
+	be := make([]byte, len(rec))
+	for i, b := range rec {
+		be[len(rec)-1-i] = b
+	}
+
+	v := new(big.Int).SetBytes(be)
+	bigP := new(big.Int).SetUint64(p)
+	d := new(big.Int)
+
+	out := make([]uint64, ne)
+	for j := range out {
+		v.QuoRem(v, bigP, d)
+		out[j] = d.Uint64()
+	}
+	if v.Sign() != 0 {
+		panic("Record does not fit in its digits")
+	}
+	return out
+}
+
+// Drops any bits above 8*n, e.g. from a corrupted answer.
+func basePToBytes(digits []uint64, p, n uint64) []byte {
+	v := new(big.Int)
+	bigP := new(big.Int).SetUint64(p)
+	for j := len(digits) - 1; j >= 0; j-- {
+		v.Mul(v, bigP)
+		v.Add(v, new(big.Int).SetUint64(digits[j]))
+	}
+
+	be := v.Bytes()
+	out := make([]byte, n)
+	for i := 0; i < len(be) && uint64(i) < n; i++ {
+		out[i] = be[len(be)-1-i]
+	}
+	return out
+}
+
+// Databases of variable-length values, stored in fixed-size slots. Value i
+// starts in slot i; a value longer than a slot continues in extra slots
+// after the last value. Each slot starts with a header: the length of the
+// value (in its first slot), and the index of the slot holding the rest of
+// it, or 0 if none.
+const valueHeaderBytes = 8
+
+// Slots hold the longest value, or at most maxSlotBytes bytes if it is
+// nonzero. Clients then need one query per slot of a value, which the
+// server sees: to hide value lengths, do not set maxSlotBytes below the
+// longest value.
+func NewDatabaseValues[T matrix.Elem](values [][]byte, maxSlotBytes uint64) *Database[T] {
+	slotBytes, slots := encodeValues(values, maxSlotBytes)
+	return NewDatabaseBytes[T](valueHeaderBytes+slotBytes, slots)
+}
+
+func encodeValues(values [][]byte, maxSlotBytes uint64) (uint64, [][]byte) {
+	slotBytes := uint64(1)
+	for _, v := range values {
+		if uint64(len(v)) > slotBytes {
+			slotBytes = uint64(len(v))
+		}
+		if uint64(len(v)) >= 1<<32 {
+			panic("Value too long")
+		}
+	}
+	if maxSlotBytes > 0 && slotBytes > maxSlotBytes {
+		slotBytes = maxSlotBytes
+	}
+
+	slots := make([][]byte, len(values))
+	for i, v := range values {
+		slot := i
+		rest := v
+		for first := true; first || len(rest) > 0; first = false {
+			n := uint64(len(rest))
+			if n > slotBytes {
+				n = slotBytes
+			}
+
+			buf := make([]byte, valueHeaderBytes+n)
+			if first {
+				binary.LittleEndian.PutUint32(buf, uint32(len(v)))
+			}
+			copy(buf[valueHeaderBytes:], rest[:n])
+			rest = rest[n:]
+
+			if len(rest) > 0 {
+				next := len(slots)
+				if next >= 1<<32 {
+					panic("Too many slots")
+				}
+				binary.LittleEndian.PutUint32(buf[4:], uint32(next))
+				slots = append(slots, nil)
+			}
+			slots[slot] = buf
+			slot = len(slots) - 1
+		}
+	}
+
+	return slotBytes, slots
+}
+
+// Answers queries, e.g. a Server or Scheduler.
+type Answerer[T matrix.Elem] interface {
+	Answer(query *Query[T]) *Answer[T]
+}
+
+// Retrieves value 'index' of a database built by NewDatabaseValues,
+// querying any continuation slots in turn.
+func (c *Client[T]) GetValue(index uint64, server Answerer[T]) ([]byte, error) {
+	slotBytes := c.dbinfo.RowLength/8 - valueHeaderBytes
+
+	var out []byte
+	var length uint64
+	for slot, first := index, true; first || uint64(len(out)) < length; first = false {
+		if slot >= c.dbinfo.Num {
+			return nil, fmt.Errorf("slot %d out of range", slot)
+		}
+
+		s, q := c.Query(slot)
+		buf := c.RecoverBytes(s, server.Answer(q))
+		if first {
+			length = uint64(binary.LittleEndian.Uint32(buf))
+		}
+
+		n := length - uint64(len(out))
+		if n > slotBytes {
+			n = slotBytes
+		}
+		out = append(out, buf[valueHeaderBytes:valueHeaderBytes+n]...)
+
+		next := uint64(binary.LittleEndian.Uint32(buf[4:]))
+		if uint64(len(out)) < length && next <= slot {
+			return nil, fmt.Errorf("value %d is truncated at slot %d", index, slot)
+		}
+		slot = next
+	}
+
+	return out, nil
+}
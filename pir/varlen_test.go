@@ -0,0 +1,103 @@
+package pir
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ryanleh/simplepir/matrix"
+	"github.com/ryanleh/simplepir/rand"
+)
+
+func randomBytes(prg *rand.BufPRGReader, n uint64) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(prg.Uint64())
+	}
+	return buf
+}
+
+func testBytes[T matrix.Elem](t *testing.T, num uint64, recordBytes uint64) {
+	prg := testPRG(t)
+	records := make([][]byte, num)
+	for i := range records {
+		records[i] = randomBytes(prg, recordBytes)
+	}
+	records[1] = records[1][:recordBytes/2] // shorter records are zero-padded
+
+	db := NewDatabaseBytes[T](recordBytes, records)
+	server, client := testSetup(prg, db)
+
+	for _, i := range []uint64{0, 1, num - 1} {
+		want := make([]byte, recordBytes)
+		copy(want, records[i])
+		if !bytes.Equal(db.GetBytes(i), want) {
+			t.Fatalf("Record %d stored as %x instead of %x", i, db.GetBytes(i), want)
+		}
+
+		s, q := client.Query(i)
+		if got := client.RecoverBytes(s, server.Answer(q)); !bytes.Equal(got, want) {
+			t.Fatalf("Querying index %d: Got %x instead of %x", i, got, want)
+		}
+	}
+
+	// Short records decode as before
+	if recordBytes <= 8 {
+		var val uint64
+		for j := len(records[0]) - 1; j >= 0; j-- {
+			val = val<<8 | uint64(records[0][j])
+		}
+		if db.GetElem(0) != val {
+			t.Fatalf("GetElem: Got %d instead of %d", db.GetElem(0), val)
+		}
+	}
+}
+
+func TestBytes32(t *testing.T) {
+	testBytes[matrix.Elem32](t, 1<<12, 3)
+	testBytes[matrix.Elem32](t, 1<<12, 100)
+}
+
+func TestBytes64(t *testing.T) {
+	testBytes[matrix.Elem64](t, 1<<10, 8)
+	testBytes[matrix.Elem64](t, 1<<10, 100)
+}
+
+func testValues[T matrix.Elem](t *testing.T, maxSlotBytes uint64) {
+	prg := testPRG(t)
+	values := make([][]byte, 300)
+	for i := range values {
+		values[i] = randomBytes(prg, prg.Uint64()%60)
+	}
+	values[0] = nil
+	values[1] = randomBytes(prg, 200)
+
+	db := NewDatabaseValues[T](values, maxSlotBytes)
+	server, client := testSetup(prg, db)
+
+	if maxSlotBytes == 0 && db.Info.Num != uint64(len(values)) {
+		t.Fatalf("Values were split into %d slots", db.Info.Num)
+	}
+	if maxSlotBytes > 0 && db.Info.RowLength != 8*(valueHeaderBytes+maxSlotBytes) {
+		t.Fatalf("Slots of %d bits", db.Info.RowLength)
+	}
+
+	for _, i := range []uint64{0, 1, 2, 299} {
+		got, err := client.GetValue(i, server)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, values[i]) {
+			t.Fatalf("Value %d: Got %x instead of %x", i, got, values[i])
+		}
+	}
+}
+
+func TestValues32(t *testing.T) {
+	testValues[matrix.Elem32](t, 0)
+	testValues[matrix.Elem32](t, 16)
+}
+
+func TestValues64(t *testing.T) {
+	testValues[matrix.Elem64](t, 0)
+	testValues[matrix.Elem64](t, 16)
+}
@@ -0,0 +1,118 @@
+package pir
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+// Returned when a query is for a database version no longer served: the
+// client must download the current hint.
+var ErrVersionRetired = errors.New("pir: database version retired")
+
+// Serves a database that is updated while live. The next version is built
+// in the background; once it is live, the previous one is still served
+// for a grace period, so that clients holding its hint keep working until
+// they refresh. Queries are routed by Query.Version.
+type VersionedServer[T matrix.Elem] struct {
+	grace     time.Duration
+	afterFunc func(time.Duration, func()) // time.AfterFunc; replaced in tests
+
+	mu       sync.RWMutex
+	current  *Server[T]
+	previous *Server[T]
+
+	latest  uint64          // highest version requested
+	pending <-chan struct{} // last update, which the next one waits for
+}
+
+func NewVersionedServer[T matrix.Elem](server *Server[T], grace time.Duration) *VersionedServer[T] {
+	return &VersionedServer[T]{
+		grace:     grace,
+		afterFunc: func(d time.Duration, f func()) { time.AfterFunc(d, f) },
+		current:   server,
+		latest:    server.db.Info.Version,
+	}
+}
+
+// The server for the latest version, e.g. to hand out its hint and DBInfo.
+func (v *VersionedServer[T]) Current() *Server[T] {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.current
+}
+
+// The versions served, latest first.
+func (v *VersionedServer[T]) Versions() []uint64 {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	out := []uint64{v.current.db.Info.Version}
+	if v.previous != nil {
+		out = append(out, v.previous.db.Info.Version)
+	}
+	return out
+}
+
+func (v *VersionedServer[T]) Answer(query *Query[T]) (*Answer[T], error) {
+	v.mu.RLock()
+	server := v.current
+	if server.db.Info.Version != query.Version {
+		server = v.previous
+	}
+	v.mu.RUnlock()
+
+	if server == nil || server.db.Info.Version != query.Version {
+		return nil, ErrVersionRetired
+	}
+	return server.Answer(query), nil
+}
+
+// Builds a server for 'db' in the background, as the given version, which
+// must be newer than any before. The server gets its own copy of db.Info,
+// so the caller's is left as it was. Updates go live in order, and the returned
+// channel is closed once this one is. Any version still in its grace period
+// is retired then, so at most two are served at once.
+func (v *VersionedServer[T]) Update(db *Database[T], version uint64) <-chan struct{} {
+	done := make(chan struct{})
+
+	v.mu.Lock()
+	if version <= v.latest {
+		v.mu.Unlock()
+		panic("Database versions must increase")
+	}
+	v.latest = version
+	prev := v.pending
+	v.pending = done
+	v.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+
+		info := *db.Info
+		info.Version = version
+		next := NewServer(&Database[T]{Info: &info, Data: db.Data})
+
+		v.mu.Lock()
+		old := v.current
+		v.previous, v.current = old, next
+		v.mu.Unlock()
+
+		v.afterFunc(v.grace, func() { v.retire(old) })
+	}()
+
+	return done
+}
+
+func (v *VersionedServer[T]) retire(server *Server[T]) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.previous == server {
+		v.previous = nil
+	}
+}
@@ -0,0 +1,92 @@
+package pir
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryanleh/simplepir/matrix"
+)
+
+func queryVersioned[T matrix.Elem](t *testing.T, v *VersionedServer[T], client *Client[T], db *Database[T], index uint64) error {
+	secret, query := client.Query(index)
+	return answerVersioned(t, v, client, db, secret, query)
+}
+
+func answerVersioned[T matrix.Elem](t *testing.T, v *VersionedServer[T], client *Client[T], db *Database[T],
+	secret *Secret[T], query *Query[T]) error {
+	ans, err := v.Answer(query)
+	if err != nil {
+		return err
+	}
+	if val := client.Recover(secret, ans); val != db.GetElem(secret.index) {
+		t.Fatalf("Querying index %d: Got %d instead of %d", secret.index, val, db.GetElem(secret.index))
+	}
+	return nil
+}
+
+func testVersioned[T matrix.Elem](t *testing.T, N uint64, d uint64) {
+	prg := testPRG(t)
+	db1 := NewDatabaseRandom[T](prg, N, d)
+	db2 := NewDatabaseRandom[T](prg, N, d)
+	db3 := NewDatabaseRandom[T](prg, N, d)
+
+	// The grace period ends when the test says so
+	grace := time.Second
+	v := NewVersionedServer(NewServerSeed(db1, testKey(prg)), grace)
+	expire := make(chan func(), 2)
+	v.afterFunc = func(d time.Duration, f func()) {
+		if d != grace {
+			t.Errorf("Grace period of %v instead of %v", d, grace)
+		}
+		expire <- f
+	}
+	s1 := v.Current()
+	client1 := NewClientWithKey(s1.Hint(), s1.MatrixA(), s1.DBInfo(), testKey(prg))
+	secret, query := client1.Query(7)
+
+	<-v.Update(db2, 2)
+	s2 := v.Current()
+	if s2.DBInfo().Version != 2 {
+		t.Fatalf("Serving version %d instead of 2", s2.DBInfo().Version)
+	}
+	if db2.Info.Version != 0 {
+		t.Fatalf("Update set the caller's DBInfo to version %d", db2.Info.Version)
+	}
+	client2 := NewClientWithKey(s2.Hint(), s2.MatrixA(), s2.DBInfo(), testKey(prg))
+
+	// Both versions are served during the grace period
+	if err := answerVersioned(t, v, client1, db1, secret, query); err != nil {
+		t.Fatal(err)
+	}
+	if err := queryVersioned(t, v, client2, db2, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	(<-expire)()
+	if vs := v.Versions(); len(vs) != 1 || vs[0] != 2 {
+		t.Fatalf("Serving versions %v after the grace period", vs)
+	}
+	if err := queryVersioned(t, v, client1, db1, 7); err != ErrVersionRetired {
+		t.Fatalf("Expected ErrVersionRetired, got %v", err)
+	}
+
+	// Versions must increase, even while an update is building
+	secret, query = client2.Query(N - 1)
+	done := v.Update(db3, 3)
+	expectPanic(t, "Database versions must increase", func() { v.Update(db1, 3) })
+	<-done
+	if vs := v.Versions(); len(vs) != 2 || vs[0] != 3 || vs[1] != 2 {
+		t.Fatalf("Serving versions %v", vs)
+	}
+	if err := answerVersioned(t, v, client2, db2, secret, query); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVersioned32(t *testing.T) {
+	testVersioned[matrix.Elem32](t, uint64(1<<16), uint64(8))
+}
+
+func TestVersioned64(t *testing.T) {
+	testVersioned[matrix.Elem64](t, uint64(1<<14), uint64(32))
+}